@@ -3,490 +3,114 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
-	"reflect"
+	"runtime"
 	"strings"
-	"text/template"
+	"time"
 
-	_ "embed"
-
-	"github.com/carlmjohnson/versioninfo"
-	"github.com/iancoleman/strcase"
-	"github.com/pb33f/libopenapi"
-	"github.com/pb33f/libopenapi/datamodel/high/base"
-	"golang.org/x/exp/slices"
-)
-
-const (
-	SorbetUntyped = "T.untyped"
+	"gitlab.com/tanna.dev/schema-sorbet/openapi"
 )
 
-type Metadata struct {
-	Command string
-	Version string
-
-	Modules []string
-
-	Spec struct {
-		Title   string
-		Version string
-	}
-}
-
-type Type struct {
-	SchemaName           string
-	TypeName             string
-	Filename             string
-	Type                 string
-	Comment              string
-	BaseClass            string
-	Properties           []Property
-	Enum                 []Enum
-	Alias                string
-	AdditionalProperties string
-
-	IsArray bool
-}
-
-func (t Type) RelativeRequires() []string {
-	required := make(map[string]bool)
-
-	for _, prop := range t.Properties {
-		if prop.Type != SorbetUntyped && prop.Type != "String" && prop.Type != "Integer" && prop.Type != "T::Boolean" {
-			filename := strcase.ToSnake(prop.Type)
-			required[filename] = true
-		}
-	}
-
-	if t.AdditionalProperties != "" && t.AdditionalProperties != SorbetUntyped &&
-		t.AdditionalProperties != "String" && t.AdditionalProperties != "Integer" {
-		filename := strcase.ToSnake(t.AdditionalProperties)
-		required[filename] = true
-	}
-
-	result := make([]string, 0, len(required))
-	for filename := range required {
-		result = append(result, "./"+filename)
-	}
-
-	slices.Sort(result)
-	return result
-}
-
-func (t Type) IsObject() bool {
-	return "T::Struct" == t.BaseClass
-}
-
-func (t Type) IsEnum() bool {
-	return len(t.Enum) > 0
-}
-
-type Property struct {
-	Ref        string
-	Name       string
-	Type       string
-	SchemaName string
-	Required   bool
-	IsArray    bool
-}
-
-type Enum struct {
-	// Name contains the Ruby name for the enum value
-	Name string
-	// Value contains the value name as defined in the schema
-	Value string
-}
-
-func (p *Property) RubyDefinition() string {
-	s := fmt.Sprintf("const :%s, ", p.Name)
-
-	ty := p.Type
-	if p.IsArray {
-		ty = fmt.Sprintf("T::Array[%s]", ty)
-	}
-
-	if p.Required {
-		s += ty
-	} else {
-		s += fmt.Sprintf("T.nilable(%s)", ty)
-	}
-
-	if p.SchemaName != p.Name {
-		s += fmt.Sprintf(", name: '%s'", p.SchemaName)
-	}
-
-	return s
-}
-
-func prepareComment(s string) string {
-	return strings.TrimSpace(s)
-}
-
-func parseString(name string, v *base.Schema) (types []Type) {
-	t := Type{}
-	t.SchemaName = name
-	t.TypeName = strcase.ToCamel(name)
-	t.Filename = strcase.ToSnake(name)
-	t.Comment = prepareComment(v.Description)
-	t.Alias = "String"
-
-	if v.Enum != nil {
-		if "string" != reflect.TypeOf(v.Enum[0]).String() {
-			log.Println("WARN: " + name + " has a non-string enum type (`  " + reflect.TypeOf(v.Enum[0]).String() + " `), which may not work with enum generation")
-		}
-		for _, enum := range v.Enum {
-			val, ok := enum.(string)
-			if !ok {
-				log.Println("WARN: " + name + " has a non-string enum type (`  " + reflect.TypeOf(val).String() + " `), which failed to have its type converted to a string")
-				continue
-			}
-
-			t.Enum = append(t.Enum, Enum{
-				Name:  strcase.ToCamel(val),
-				Value: val,
-			})
-		}
-	}
-
-	types = append(types, t)
-
-	// TODO pattern
-	// TODO format
-	return types
-}
-
-func parseBoolean(name string, v *base.Schema) (types []Type) {
-	t := Type{}
-	t.SchemaName = name
-	t.TypeName = strcase.ToCamel(name)
-	t.Filename = strcase.ToSnake(name)
-	t.Comment = prepareComment(v.Description)
-	t.Alias = "T::Boolean"
-
-	types = append(types, t)
-	return
-}
-
-func parseObject(name string, v *base.Schema) (types []Type) {
-	t := Type{}
-	t.SchemaName = name
-	t.TypeName = strcase.ToCamel(name)
-	t.Filename = strcase.ToSnake(name)
-	t.Comment = prepareComment(v.Description)
-	t.BaseClass = "T::Struct"
-
-	for propertyName, v2 := range v.Properties {
-		prop := Property{
-			Name:       strcase.ToSnake(propertyName),
-			SchemaName: propertyName,
-			Type:       SorbetUntyped,
-			Required:   slices.Contains(v.Required, propertyName),
-		}
-
-		if v2.IsReference() {
-			parts := strings.Split(v2.GetReference(), "/")
-			prop.Type = strcase.ToCamel(parts[len(parts)-1])
-		} else {
-			schema := v2.Schema()
-			if len(schema.Type) == 0 {
-				log.Printf("Skipping property %s.%s as no Type was present", name, propertyName)
-				continue
-			}
-
-			switch schema.Type[0] { //TODO
-			case "string":
-				prop.Type = "String"
-			case "boolean":
-				prop.Type = "T::Boolean"
-			case "integer":
-				prop.Type = "Integer"
-			case "object":
-				objectTypeName := name + "_" + propertyName
-
-				childTypes := parseObject(objectTypeName, schema)
-				types = append(types, childTypes...)
-
-				prop.Type = strcase.ToCamel(objectTypeName)
-			case "array":
-				prop.IsArray = true
-				prop.Type = SorbetUntyped
-
-				if schema.Items.IsB() {
-					// do nothing
-				} else if schema.Items.IsA() {
-					s := schema.Items.A
-					if s.IsReference() {
-						parts := strings.Split(s.GetReference(), "/")
-						prop.Type = strcase.ToCamel(parts[len(parts)-1])
-					} else {
-						schema := s.Schema()
-						if len(schema.Type) > 0 {
-							switch schema.Type[0] { //TODO
-							case "string":
-								prop.Type = "String"
-							case "integer":
-								prop.Type = "Integer"
-							default:
-								log.Printf("%s had an unmatched v.Items.Schema.Type in parseObject: %#v\n", name, schema.Type[0])
-							}
-						} else {
-							log.Printf("%s had an unset v.Items.Schema.Type in parseObject: %#v\n", name, schema.Type)
-						}
-					}
-				} else {
-					log.Printf("%s.%s had an unmatched v.Type in parseObject: %#v\n", name, propertyName, schema.Type[0])
-				}
-			default:
-				log.Printf("%s.%s had an unmatched v.Type in parseObject: %#v\n", name, propertyName, schema.Type[0])
+func main() {
+	var opts openapi.Options
+	flag.Func("path", "Path to OpenAPI document, `-` to read it from stdin, or an HTTP(S) URL to fetch it from. Can be repeated, comma-separated, or a glob pattern, i.e. `specs/*.yaml`, to merge the schemas of multiple documents", func(s string) error {
+		var paths []string
+		err := listFlag(&paths)(s)
+		for _, path := range paths {
+			if opts.Path == "" {
+				opts.Path = path
+			} else {
+				opts.ExtraPaths = append(opts.ExtraPaths, path)
 			}
 		}
-
-		t.Properties = append(t.Properties, prop)
-	}
-
-	// ensure that we have consistent output
-	slices.SortStableFunc(t.Properties, func(a, b Property) bool {
-		return a.Name < b.Name
+		return err
 	})
-
-	if v.AdditionalProperties == true {
-		t.AdditionalProperties = SorbetUntyped
-	} else if v.AdditionalProperties != nil {
-		sp, ok := v.AdditionalProperties.(*base.SchemaProxy)
-		if ok {
-			schema := sp.Schema()
-
-			if len(schema.Type) > 0 {
-				switch schema.Type[0] { //TODO
-				case "string":
-					t.AdditionalProperties = "String"
-				case "integer":
-					t.AdditionalProperties = "Integer"
-				default:
-					log.Printf("%s had an unmatched v.AdditionalProperties in parseObject: %#v\n", name, schema.Type[0])
-				}
-			} else {
-				log.Printf("%s had an unmatched v.AdditionalProperties in parseObject: %#v\n", name, schema.Type)
-			}
+	flag.DurationVar(&opts.Timeout, "timeout", 30*time.Second, "Timeout when fetching the OpenAPI document over HTTP(S)")
+	flag.Func("include", "Only generate schemas whose name matches the regular expression. Can be repeated, or comma-separated", listFlag(&opts.Include))
+	flag.Func("exclude", "Don't generate schemas whose name matches the regular expression. Can be repeated, or comma-separated", listFlag(&opts.Exclude))
+	flag.BoolVar(&opts.Paths, "paths", false, "Also generate types from the inline schemas of request and response bodies under `paths`, named after their operation, i.e. `CreateUserRequest` or `CreateUser201Response`")
+	flag.BoolVar(&opts.ResponsesEnum, "responses-enum", false, "Also generate an enum for each operation under `paths` of the status codes declared in its `responses`, named after the operation, i.e. `CreateUserResponses`")
+	flag.BoolVar(&opts.ReportUnused, "report-unused", false, "Log the generated types which aren't referenced by any other type, nor by an operation, as their schemas may no longer be needed")
+	flag.BoolVar(&opts.Strict, "strict", false, "Fail if any diagnostics, such as an unsupported type, are reported while parsing the schemas")
+	flag.StringVar(&opts.Module, "module", "", "")
+	flag.StringVar(&opts.ModuleStyle, "module-style", openapi.ModuleStyleNested, "How to open the -module, either `nested` for a line per module, or `compact` for a single line, i.e. `module Api::V1`, which requires its parent modules to already be defined")
+	flag.StringVar(&opts.Indent, "indent", openapi.IndentSpaces, "How to indent generated code, either `2` for two spaces, or `tab` for tabs")
+	flag.StringVar(&opts.Out, "out", "out", "Directory to write the generated files to, or `-` to write to stdout when used with -single-file")
+	flag.StringVar(&opts.Format, "format", openapi.FormatRB, "Kind of files to generate, either `rb` for Ruby defining the types, `rbi` for RBI files declaring their signatures, or `rbs` for RBS signatures")
+	flag.StringVar(&opts.OutFileExt, "out-file-ext", "", "Extension of the files that types are generated in, including the leading dot, i.e. `.gen.rb`. Defaults to that of the -format, and must end in .rb for Ruby files")
+	flag.StringVar(&opts.Template, "template", "", "Path to a Go template file to render each type's file with, instead of the default, which receives the same `.Metadata` and `.Type`")
+	flag.BoolVar(&opts.NoTimestamp, "no-timestamp", false, "Omit the time of generation from generated files' header, so output is reproducible")
+	flag.IntVar(&opts.Jobs, "jobs", runtime.GOMAXPROCS(0), "Number of files to render concurrently")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "Log the files that would be generated, and their sizes, without writing them")
+	flag.BoolVar(&opts.Check, "check", false, "Compare the files that would be generated against those in the output directory, without writing them, and fail if any differ, are missing, or are no longer generated. Requires -no-timestamp")
+	flag.BoolVar(&opts.Clean, "clean", false, "Remove previously generated files which are no longer generated from the output directory")
+	flag.StringVar(&opts.SingleFile, "single-file", "", "Write all types to a single file of the given name, i.e. `types.rb`, instead of a file per type")
+	flag.StringVar(&opts.Manifest, "manifest", "", "Write a JSON array to the given path, i.e. `manifest.json`, describing each generated type's schema name, type name, file, kind and properties")
+	flag.BoolVar(&opts.Servers, "servers", false, "Also generate a `Servers` module, with a `BASE_URL` constant of the URL of the document's first server, and a `URLS` constant of every server's URL, leaving server variables as `{name}` placeholders")
+	flag.StringVar(&opts.Fixtures, "fixtures", "", "Write a file to the given directory, i.e. `spec/fixtures`, for each type whose schema has an `example` or `examples`, defining a method of a `Fixtures` module that returns an instance of the type constructed from each example")
+	flag.StringVar(&opts.Index, "index", "", "Write a file of the given name, i.e. `all.rb`, to the root of the output directory, which requires every generated type")
+	flag.BoolVar(&opts.Zeitwerk, "zeitwerk", false, "Fail if any generated module or type would not be autoloaded by Zeitwerk from the directory or file it is generated in")
+	flag.StringVar(&opts.EnumStyle, "enum-style", openapi.EnumStyleTEnum, "How to generate enums, either `tenum` for a T::Enum class, or `alias` for a type alias of the underlying type")
+	flag.StringVar(&opts.AllOfStyle, "allof-style", openapi.AllOfStyleFlatten, "How to generate `allOf` schemas, either `flatten` to merge all members' properties, or `inherit` to subclass a single `$ref` member")
+	flag.StringVar(&opts.AliasStyle, "alias-style", openapi.AliasStyleType, "How to generate schemas that aren't structs or enums, either `type` for a type alias, or `wrapper` for a class with a typed `value`")
+	flag.BoolVar(&opts.Examples, "examples", false, "Add the `example` and `examples` of schemas and properties to their comments, as JSON")
+	flag.StringVar(&opts.Role, "role", openapi.RoleBoth, "Kind of bodies the types are used for, either `request` to omit `readOnly` properties, `response` to omit `writeOnly` properties, or `both` to keep them with a comment")
+	flag.BoolVar(&opts.InterfaceBases, "interface-bases", false, "Generate object schemas that are only used as an `allOf` base of two or more schemas as an interface module, which their children include, rather than a struct")
+	flag.BoolVar(&opts.TypedMaps, "typed-maps", false, "Key the `additionalProperties` of objects by String, as `T::Hash[String, ...]`, rather than by either a Symbol or String")
+	flag.StringVar(&opts.TypedSigil, "typed-sigil", "true", "Strictness level of the `# typed:` sigil for generated files, one of "+strings.Join(openapi.TypedSigils, ", "))
+	flag.BoolVar(&opts.Mutable, "mutable", false, "Generate structs' properties with `prop`, rather than `const`, so they can be modified. Can be overridden per schema with the `x-sorbet-mutable` extension")
+	flag.BoolVar(&opts.Validations, "validations", false, "Generate a `validate!` method on structs, enforcing the `pattern`, `minLength` and `maxLength` of string properties, the `minimum`, `maximum` and `multipleOf` of numeric properties, and that `format: uuid` properties are UUIDs, when deserialized from a hash")
+	flag.BoolVar(&opts.CollectionDefaults, "collection-defaults", false, "Default optional array and map properties, without a `default`, to an empty collection, i.e. `default: []`, rather than making them nilable")
+	flag.BoolVar(&opts.ClosedObjects, "closed-objects", false, "Reject undeclared keys when deserializing structs of objects with `additionalProperties: false` from a hash, rather than ignoring them")
+	flag.BoolVar(&opts.Serializers, "serializers", false, "Include `T::Props::Serializable` in structs, to convert them to and from hashes using the schemas' property names")
+	flag.BoolVar(&opts.PreferTitle, "prefer-title", false, "Name classes after their schema's `title`, if set, rather than the schema's name. Files are still named after the schema's name")
+	flag.BoolVar(&opts.Inflect, "inflect", false, "Name the child types of inline array items after the singular of the array's name, i.e. PetTag for the items of Pet.tags, rather than PetTagsItem")
+	flag.BoolVar(&opts.HoistUnions, "hoist-unions", false, "Generate a `T.any` union which is the type of more than one property as a named type alias, i.e. `StringOrInteger`, rather than repeating it")
+	flag.StringVar(&opts.ClassCase, "class-case", openapi.NameCasePascal, "Case of generated class names, one of "+strings.Join(openapi.NameCases, ", "))
+	flag.StringVar(&opts.FileCase, "file-case", openapi.NameCaseSnake, "Case of generated file names, one of "+strings.Join(openapi.NameCases, ", "))
+	flag.StringVar(&opts.Int64Type, "int64-type", "", "Sorbet type to use for `format: int64` integers, instead of Integer")
+	flag.StringVar(&opts.DateTimeType, "date-time-type", "", "Sorbet type to use for `format: date-time` strings, instead of String")
+	flag.StringVar(&opts.BinaryType, "binary-type", "", "Sorbet type to use for `format: binary` strings, such as file uploads, instead of String")
+	flag.StringVar(&opts.ByteType, "byte-type", "", "Sorbet type to use for `format: byte` base64-encoded strings, instead of String")
+	flag.StringVar(&opts.UUIDType, "uuid-type", "", "Sorbet type to use for `format: uuid` strings, instead of String")
+	flag.StringVar(&opts.DecimalType, "decimal-type", "", "Sorbet type to use for `format: decimal` strings and numbers, instead of BigDecimal")
+	flag.Func("format-type", "Sorbet type to use for strings of a given format, instead of String, as `format=Type`, i.e. `uuid=UUID`. Can be repeated", func(s string) error {
+		format, ty, ok := strings.Cut(s, "=")
+		if !ok || format == "" || ty == "" {
+			return fmt.Errorf("expected `format=Type`, but received %#v", s)
 		}
-	}
-
-	types = append(types, t)
-
-	return types
-}
-
-func parseArray(name string, v *base.Schema) (types []Type) {
-	t := Type{}
-	t.SchemaName = name
-	t.TypeName = strcase.ToCamel(name)
-	t.Filename = strcase.ToSnake(name)
-	t.Comment = prepareComment(v.Description)
-	t.Alias = SorbetUntyped
-	t.IsArray = true
-
-	// IsB here is whether this is an `items: true`
-	if v.Items.IsB() {
-		t.Alias = ""
-		t.AdditionalProperties = SorbetUntyped
-	} else if v.Items.IsA() {
-		s := v.Items.A
-		if s.IsReference() {
-			parts := strings.Split(s.GetReference(), "/")
-			t.Alias = parts[len(parts)-1]
-		} else {
-			schema := s.Schema()
-			if len(schema.Type) > 0 {
-				switch schema.Type[0] { //TODO
-				case "string":
-					t.Alias = "String"
-				case "integer":
-					t.Alias = "Integer"
-				default:
-					log.Printf("%s had an unmatched v.Items.Schema.Type in parseArray: %#v\n", name, schema.Type[0])
-				}
-			} else {
-				log.Printf("%s had an unset v.Items.Schema.Type in parseArray: %#v\n", name, schema.Type)
-			}
+		if opts.StringFormatTypes == nil {
+			opts.StringFormatTypes = make(map[string]string)
 		}
-	}
-
-	types = append(types, t)
-
-	return
-}
+		opts.StringFormatTypes[format] = ty
+		return nil
+	})
+	flag.StringVar(&opts.LogLevel, "log-level", openapi.LogLevelInfo, "Minimum level of messages to log, one of "+strings.Join(openapi.LogLevels, ", "))
+	flag.StringVar(&opts.LogFormat, "log-format", openapi.LogFormatText, "Format to log messages in, either `text`, or `json` for a JSON object per line")
+	version := flag.Bool("version", false, "Print the version of openapi-sorbet and exit")
+	frozenStringLiteral := flag.Bool("frozen-string-literal", true, "Add the `# frozen_string_literal: true` magic comment to generated Ruby files")
+	flag.Parse()
 
-func parseSchema(name string, v *base.Schema) (types []Type) {
-	if len(v.Type) == 0 {
-		log.Printf("Skipping %s as no Type was present", name)
+	if *version {
+		fmt.Printf("openapi-sorbet version %s\n", openapi.Version())
 		return
 	}
 
-	switch v.Type[0] { // TODO
-	case "string":
-		types = append(types, parseString(name, v)...)
-	case "boolean":
-		types = append(types, parseBoolean(name, v)...)
-	case "object":
-		types = append(types, parseObject(name, v)...)
-	case "array":
-		types = append(types, parseArray(name, v)...)
-	default:
-		log.Printf("%s had an unmatched v.Value.Type in parseSchema: %#v\n", name, v.Type)
-	}
-
-	return
-}
+	opts.NoFrozenStringLiteral = !*frozenStringLiteral
 
-func parseModules(module string) []string {
-	modules := strings.Split(module, "::")
-	if len(modules) == 1 && modules[0] == "" {
-		modules = nil
-	}
-
-	return modules
-}
-
-func parseVersion() string {
-	version := versioninfo.Short()
-	if version == "" {
-		version = "(unknown)"
+	_, err := openapi.Generate(opts)
+	if err != nil {
+		openapi.Fatal(opts.LogFormat, err)
 	}
-	return version
 }
 
-//go:embed class.rb.tmpl
-var rawClassTemplate string
-
-//go:embed hash_deserializable.rb.tmpl
-var rawHashDeserializableTemplate string
-
-func main() {
-	var path string
-	var module string
-	var out string
-	flag.StringVar(&path, "path", "", "Path to OpenAPI document")
-	flag.StringVar(&module, "module", "", "")
-	flag.StringVar(&out, "out", "out", "")
-	flag.Parse()
-
-	docBytes, err := os.ReadFile(path)
-	must(err)
-
-	document, err := libopenapi.NewDocument(docBytes)
-	must(err)
-
-	d, errors := document.BuildV3Model()
-	if len(errors) > 0 {
-		log.Printf("Failed to build OpenAPI v3 model for %s\n", path)
-		for _, err2 := range errors {
-			log.Println(err2)
-		}
-		log.Fatal("^^")
-	}
-
-	classTemplate, err := template.New("").Funcs(template.FuncMap{}).Parse(rawClassTemplate)
-	must(err)
-
-	var allTypes []Type
-
-	for k, sp := range d.Model.Components.Schemas {
-		if sp.IsReference() {
-			log.Printf("Skipping %s as ref", k)
-			continue
-		}
-
-		schema := sp.Schema()
-		types := parseSchema(k, schema)
-		if len(types) == 0 {
-			log.Printf("Missing type data for schema %s\n", k)
-		}
-		allTypes = append(allTypes, types...)
-	}
-
-	modules := parseModules(module)
-
-	// TODO
-	outPathParts := []string{out}
-
-	for _, m := range modules {
-		outPathParts = append(outPathParts, strcase.ToSnake(m))
-	}
-
-	outPath := filepath.Join(outPathParts...)
-	// TODO
-
-	err = os.MkdirAll(outPath, os.ModePerm)
-	must(err)
-
-	metadata := Metadata{
-		Command: "openapi-sorbet",
-		Version: parseVersion(),
-
-		Modules: modules,
-	}
-	metadata.Spec.Title = d.Model.Info.Title
-	metadata.Spec.Version = d.Model.Info.Version
-
-	for _, t := range allTypes {
-		data := struct {
-			Metadata Metadata
-			Type     Type
-		}{
-			Metadata: metadata,
-			Type:     t,
+// listFlag returns a flag.Func that appends each comma-separated value to values
+func listFlag(values *[]string) func(string) error {
+	return func(s string) error {
+		for _, value := range strings.Split(s, ",") {
+			if value != "" {
+				*values = append(*values, value)
+			}
 		}
-
-		f, err := os.Create(filepath.Join(outPath, t.Filename) + ".rb")
-		must(err)
-
-		err = classTemplate.Execute(f, data)
-		must(err)
-
-		err = f.Close()
-		must(err)
-	}
-
-	// Create types.rb file
-	typesFile, err := os.Create(filepath.Join(outPath, "types.rb"))
-	must(err)
-	defer typesFile.Close()
-
-	// Write requires for all generated type files
-	sortedTypes := make([]Type, len(allTypes))
-	copy(sortedTypes, allTypes)
-	slices.SortFunc(sortedTypes, func(a, b Type) bool {
-		return a.Filename < b.Filename
-	})
-	for _, t := range sortedTypes {
-		_, err := fmt.Fprintf(typesFile, "require_relative '%s'\n", t.Filename)
-		must(err)
-	}
-
-	fmt.Println("Generated types.rb with all type requires")
-
-	// Render hash_deserializable template
-	hashDeserializableFile, err := os.Create(filepath.Join(outPath, "hash_deserializable.rb"))
-	must(err)
-	defer hashDeserializableFile.Close()
-
-	toplevelData := struct {
-		Metadata Metadata
-	}{
-		Metadata: metadata,
-	}
-	hashDeserializableTemplate, err := template.New("").Funcs(template.FuncMap{}).Parse(rawHashDeserializableTemplate)
-	must(err)
-	err = hashDeserializableTemplate.Execute(hashDeserializableFile, toplevelData)
-	must(err)
-
-	fmt.Println("Generated hash_deserializable.rb")
-}
-
-func must(err error) {
-	if err != nil {
-		log.Fatal(err)
+		return nil
 	}
 }
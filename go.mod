@@ -7,6 +7,7 @@ require (
 	github.com/iancoleman/strcase v0.2.0
 	github.com/pb33f/libopenapi v0.8.5
 	golang.org/x/exp v0.0.0-20221023144134-a1e5550cf13e
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -14,5 +15,4 @@ require (
 	github.com/stretchr/testify v1.8.1 // indirect
 	github.com/vmware-labs/yaml-jsonpath v0.3.2 // indirect
 	golang.org/x/sync v0.1.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"fmt"
+)
+
+// DiagnosticKind categorises a Diagnostic by its effect on the generated types
+type DiagnosticKind string
+
+const (
+	// DiagnosticSkipped indicates that a schema or property wasn't generated
+	DiagnosticSkipped DiagnosticKind = "skipped"
+	// DiagnosticUntyped indicates that a type couldn't be determined, so was generated as `T.untyped`
+	DiagnosticUntyped DiagnosticKind = "untyped"
+	// DiagnosticWarning indicates that a schema was generated, but may not be exactly as described
+	DiagnosticWarning DiagnosticKind = "warning"
+)
+
+// Diagnostic describes a problem found while parsing a schema
+type Diagnostic struct {
+	// Schema is the name of the schema that the problem was found in
+	Schema string
+	// Property is the path to the property within the Schema that the problem was found in, if any
+	Property string
+	Kind     DiagnosticKind
+	Message  string
+}
+
+// String returns the path to the schema or property that the problem was found in, followed by the Message
+func (d Diagnostic) String() string {
+	return schemaPath(d.Schema, d.Property) + " " + d.Message
+}
+
+// schemaPath returns the path to the property within the schema, or the schema itself if there is no property
+func schemaPath(schema string, property string) string {
+	if property == "" {
+		return schema
+	}
+	return schema + "." + property
+}
+
+// report records a Diagnostic for the schema, or the property within it if set, logging it as it occurs
+func (p *parser) report(kind DiagnosticKind, schema string, property string, format string, args ...any) {
+	d := Diagnostic{
+		Schema:   schema,
+		Property: property,
+		Kind:     kind,
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	p.log.diagnostic(d)
+	p.diagnostics = append(p.diagnostics, d)
+}
+
+// summarizeDiagnostics returns a consolidated summary of the diagnostics, by their kind
+func summarizeDiagnostics(diagnostics []Diagnostic) string {
+	counts := make(map[DiagnosticKind]int)
+	for _, d := range diagnostics {
+		counts[d.Kind]++
+	}
+	return fmt.Sprintf("%d schemas or properties skipped, %d types untyped, %d other warnings", counts[DiagnosticSkipped], counts[DiagnosticUntyped], counts[DiagnosticWarning])
+}
@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	spec := specWithSchemas(`
+    io.k8s.api.core.v1.Pod:
+      type: object
+      properties:
+        name:
+          type: string
+        spec:
+          type: foo
+`)
+
+	opts := Options{Input: []byte(spec), Out: t.TempDir()}
+	_, logs := generateSpecLogs(t, "", opts)
+	for _, want := range []string{
+		"WARN: io.k8s.api.core.v1.Pod.spec ",
+		"Parsed with diagnostics: 0 schemas or properties skipped, 1 types untyped, 0 other warnings",
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs did not contain %q:\n%s", want, logs)
+		}
+	}
+
+	opts.Strict = true
+	opts.Out = t.TempDir()
+	result, err := Generate(opts)
+	if err == nil || !strings.Contains(err.Error(), "1 diagnostics were reported while parsing") {
+		t.Fatalf("Generate() returned the error %v, want it to report the diagnostics as Strict is enabled", err)
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Fatalf("Generate() returned %d diagnostics, want 1: %v", len(result.Diagnostics), result.Diagnostics)
+	}
+
+	got := result.Diagnostics[0]
+	got.Message = ""
+	want := Diagnostic{Schema: "io.k8s.api.core.v1.Pod", Property: "spec", Kind: DiagnosticUntyped}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() returned the diagnostic %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,331 @@
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"golang.org/x/exp/slices"
+)
+
+// fixture is a method which returns an instance of a generated type, constructed from an example in its schema
+type fixture struct {
+	// Name is the name of the method
+	Name string
+	// Value is the Ruby expression which constructs the instance
+	Value string
+}
+
+// schemaExamples returns the `example`, followed by any `examples`, of each of the schemas, by the schema's name
+func schemaExamples(schemas map[string]*base.SchemaProxy) map[string][]any {
+	examples := make(map[string][]any)
+	for name, sp := range schemas {
+		if isSchemaRef(sp) {
+			continue
+		}
+		schema := sp.Schema()
+		if schema == nil {
+			continue
+		}
+		if schema.Example != nil {
+			examples[name] = append(examples[name], schema.Example)
+		}
+		examples[name] = append(examples[name], schema.Examples...)
+	}
+	return examples
+}
+
+// writeFixtures writes a file of fixtures for each of the types whose schema has examples to the Fixtures directory,
+// if one is configured. Each fixture is a method of a `Fixtures` module, named after the type, which returns an
+// instance of the type constructed from an example
+func writeFixtures(w *fileWriter, opts Options, templates *template.Template, metadata Metadata, outPath string, types []Type, examples map[string][]any) error {
+	if opts.Fixtures == "" {
+		return nil
+	}
+
+	b := fixtureBuilder{log: w.log, types: make(map[string]Type, len(types))}
+	for _, t := range types {
+		b.types[t.TypeName] = t
+	}
+
+	err := w.mkdirAll(opts.Fixtures)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range types {
+		values := examples[t.SchemaName]
+		if len(values) == 0 {
+			continue
+		}
+
+		var fixtures []fixture
+		for i, v := range values {
+			name := strcase.ToSnake(t.TypeName)
+			if i > 0 {
+				name = fmt.Sprintf("%s_%d", name, i+1)
+			}
+			fixtures = append(fixtures, fixture{Name: name, Value: b.value(t.TypeName, v)})
+		}
+
+		file := t.Filename + metadata.RequireSuffix
+		if opts.SingleFile != "" {
+			file = strings.TrimSuffix(opts.SingleFile, ".rb")
+		}
+		require, err := filepath.Rel(opts.Fixtures, filepath.Join(outPath, file))
+		if err != nil {
+			return err
+		}
+
+		data := struct {
+			Metadata Metadata
+			Type     Type
+			Require  string
+			Fixtures []fixture
+		}{
+			Metadata: metadata,
+			Type:     t,
+			Require:  filepath.ToSlash(require),
+			Fixtures: fixtures,
+		}
+
+		err = renderFile(w, filepath.Join(opts.Fixtures, t.Filename+".rb"), templates, "fixture.rb.tmpl", data)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.generated("Generated fixtures in %s", opts.Fixtures)
+
+	return nil
+}
+
+// fixtureBuilder renders examples as Ruby expressions which construct the generated types
+type fixtureBuilder struct {
+	// log is the logger that examples which can't be constructed as their type are logged to
+	log *logger
+	// types contains each of the generated types, by their name
+	types map[string]Type
+}
+
+// value returns the Ruby expression for an example of the given Sorbet type. Values that can't be constructed as the
+// type, such as those of a custom string format's type, are rendered as a literal
+func (b fixtureBuilder) value(ty string, v any) string {
+	if v == nil {
+		return "nil"
+	}
+
+	if inner, ok := unwrapType(ty, "T.nilable(", ")"); ok {
+		return b.value(inner, v)
+	}
+	if inner, ok := unwrapType(ty, "T::Array[", "]"); ok {
+		return b.array(inner, v)
+	}
+	if members, ok := unionMembersOf(ty); ok {
+		return b.value(b.matchingMember(members, v), v)
+	}
+	if ty == "BigDecimal" {
+		return fmt.Sprintf("BigDecimal(%s)", rubyString(fmt.Sprint(v)))
+	}
+
+	t, ok := b.types[ty]
+	if !ok {
+		return b.literal(ty, v)
+	}
+
+	switch {
+	case t.IsRefAlias:
+		return b.value(t.Alias, v)
+	case t.IsEnum():
+		if i := slices.IndexFunc(t.Enum, func(e Enum) bool { return e.Value == fmt.Sprint(v) }); i >= 0 {
+			return t.TypeName + "::" + t.Enum[i].Name
+		}
+		b.log.warnf("The example %#v isn't one of the values of %s, so will be used as is", v, t.TypeName)
+		return b.literal("", v)
+	case t.IsObject() && !t.IsMap:
+		return b.object(t, v)
+	case t.Discriminator != nil:
+		return b.discriminated(t, v)
+	case t.IsWrapper:
+		return fmt.Sprintf("%s.new(%s)", t.TypeName, b.alias(t, v))
+	case t.IsAlias():
+		return b.alias(t, v)
+	}
+
+	return b.literal("", v)
+}
+
+// alias returns the Ruby expression for an example of a type alias
+func (b fixtureBuilder) alias(t Type, v any) string {
+	if t.IsMap {
+		return b.hash(t.AdditionalProperties, v)
+	}
+	if t.IsArray {
+		return b.array(t.Alias, v)
+	}
+	if t.Alias == "" {
+		return b.literal("String", v)
+	}
+	return b.value(t.Alias, v)
+}
+
+// object returns the Ruby expression constructing a struct from an example object, including the properties of any
+// base classes it inherits
+func (b fixtureBuilder) object(t Type, v any) string {
+	example, ok := v.(map[string]any)
+	if !ok {
+		b.log.warnf("The example %#v of %s isn't an object, so will be used as is", v, t.TypeName)
+		return b.literal("", v)
+	}
+
+	properties := t.Properties
+	for base, ok := b.types[t.BaseClass]; ok; base, ok = b.types[base.BaseClass] {
+		properties = append(slices.Clone(base.Properties), properties...)
+	}
+
+	var args []string
+	declared := make(map[string]bool)
+	for _, prop := range properties {
+		declared[prop.SchemaName] = true
+
+		value, ok := example[prop.SchemaName]
+		if !ok {
+			if prop.Required && prop.Default == "" {
+				b.log.warnf("The example of %s is missing the required property %s", t.TypeName, prop.SchemaName)
+			}
+			continue
+		}
+
+		ty := prop.Type
+		if prop.IsArray {
+			ty = fmt.Sprintf("T::Array[%s]", ty)
+		}
+		args = append(args, fmt.Sprintf("%s: %s", prop.Name, b.value(ty, value)))
+	}
+
+	extra := make(map[string]any)
+	for key, value := range example {
+		if !declared[key] {
+			extra[key] = value
+		}
+	}
+	if len(extra) > 0 && t.AdditionalProperties != "" {
+		args = append(args, fmt.Sprintf("additional_properties: %s", b.hash(t.AdditionalProperties, extra)))
+	} else if len(extra) > 0 {
+		b.log.warnf("The example of %s has undeclared properties, which will be ignored: %s", t.TypeName, strings.Join(sortedKeys(extra), ", "))
+	}
+
+	if len(args) == 0 {
+		return t.TypeName + ".new"
+	}
+	return fmt.Sprintf("%s.new(\n%s\n)", t.TypeName, indent(1, strings.Join(args, ",\n")))
+}
+
+// discriminated returns the Ruby expression constructing the member of a discriminated type that an example object's
+// discriminator property refers to
+func (b fixtureBuilder) discriminated(t Type, v any) string {
+	if example, ok := v.(map[string]any); ok {
+		value := fmt.Sprint(example[t.Discriminator.PropertyName])
+		for _, m := range t.Discriminator.Mapping {
+			if m.Value == value {
+				return b.value(m.TypeName, v)
+			}
+		}
+	}
+
+	b.log.warnf("The example %#v of %s doesn't match any of its members, so will be used as is", v, t.TypeName)
+	return b.literal("", v)
+}
+
+// array returns the Ruby expression for an example array, whose items are of the given Sorbet type
+func (b fixtureBuilder) array(ty string, v any) string {
+	items, ok := v.([]any)
+	if !ok {
+		return b.literal("", v)
+	}
+
+	values := make([]string, 0, len(items))
+	multiline := false
+	for _, item := range items {
+		value := b.value(ty, item)
+		multiline = multiline || strings.Contains(value, "\n")
+		values = append(values, value)
+	}
+
+	if !multiline {
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	return fmt.Sprintf("[\n%s\n]", indent(1, strings.Join(values, ",\n")))
+}
+
+// hash returns the Ruby expression for an example object used as a hash, whose values are of the given Sorbet type
+func (b fixtureBuilder) hash(ty string, v any) string {
+	example, ok := v.(map[string]any)
+	if !ok {
+		return b.literal("", v)
+	}
+	if len(example) == 0 {
+		return "{}"
+	}
+
+	var pairs []string
+	for _, key := range sortedKeys(example) {
+		pairs = append(pairs, fmt.Sprintf("%s => %s", rubyString(key), b.value(ty, example[key])))
+	}
+	return fmt.Sprintf("{\n%s\n}", indent(1, strings.Join(pairs, ",\n")))
+}
+
+// literal returns the Ruby literal for an example value, which isn't constructed as a generated type. The alias is
+// used to render whole numbers as a Float, when that's the type
+func (b fixtureBuilder) literal(alias string, v any) string {
+	switch e := v.(type) {
+	case []any:
+		return b.array(SorbetUntyped, e)
+	case map[string]any:
+		return b.hash(SorbetUntyped, e)
+	}
+
+	if lit, ok := rubyLiteral(v, alias); ok {
+		return lit
+	}
+	return rubyString(fmt.Sprint(v))
+}
+
+// matchingMember returns the member of a union which an example is most likely to be, by comparing the kind of value
+// to the kind of each member, or the first member if none match
+func (b fixtureBuilder) matchingMember(members []string, v any) string {
+	for _, member := range members {
+		t, generated := b.types[member]
+		var ok bool
+		switch e := v.(type) {
+		case map[string]any:
+			ok = generated && (t.IsObject() || t.IsMap || t.Discriminator != nil)
+		case []any:
+			ok = strings.HasPrefix(member, "T::Array[") || (generated && t.IsArray)
+		case string:
+			ok = member == "String" || (generated && t.IsEnum() && slices.IndexFunc(t.Enum, func(en Enum) bool { return en.Value == e }) >= 0)
+		case bool:
+			ok = member == "T::Boolean"
+		case float64:
+			ok = member == "Float" || (member == "Integer" && e == float64(int64(e)))
+		case int, int64:
+			ok = member == "Integer" || member == "Float"
+		}
+		if ok {
+			return member
+		}
+	}
+	return members[0]
+}
+
+// unwrapType returns the type within a generic Sorbet type, i.e. `String` for `T.nilable(String)`, if the type has
+// the given prefix and suffix
+func unwrapType(ty string, prefix string, suffix string) (string, bool) {
+	if !strings.HasPrefix(ty, prefix) || !strings.HasSuffix(ty, suffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(ty, prefix), suffix), true
+}
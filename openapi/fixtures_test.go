@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixtures(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        status: {$ref: '#/components/schemas/Status'}
+        weight: {type: number}
+        tags: {type: array, items: {type: string}}
+        owner: {$ref: '#/components/schemas/Owner'}
+      example:
+        name: Rex
+        status: available
+        weight: 12
+        tags: [good, dog]
+        owner: {id: 1}
+    Owner:
+      type: object
+      properties:
+        id: {type: integer}
+    Status:
+      type: string
+      enum: [available, sold]
+`)
+
+	out := t.TempDir()
+	fixtures := filepath.Join(out, "fixtures")
+	generateSpec(t, spec, Options{Out: filepath.Join(out, "types"), Fixtures: fixtures})
+
+	files := readFiles(t, fixtures)
+	assertContains(t, files, "pet.rb",
+		"require_relative '../types/pet'",
+		"module Fixtures\n",
+		"  sig { returns(Pet) }\n  def self.pet\n    Pet.new(\n",
+		"      name: 'Rex',\n      owner: Owner.new(\n        id: 1\n      ),\n      status: Status::Available,\n      tags: ['good', 'dog'],\n      weight: 12.0\n    )\n",
+	)
+	for _, file := range []string{"owner.rb", "status.rb"} {
+		if _, ok := files[file]; ok {
+			t.Errorf("%s was generated, but its schema has no examples", file)
+		}
+	}
+
+	_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), Fixtures: fixtures, Format: FormatRBI})
+	if want := "Fixtures can only be generated with the rb Format"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+	}
+}
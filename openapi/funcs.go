@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nilable wraps a Sorbet type in `T.nilable`, unless it can already be `nil`
+func nilable(ty string) string {
+	if strings.HasPrefix(ty, "T.nilable(") || ty == "T.untyped" || ty == "NilClass" {
+		return ty
+	}
+	return fmt.Sprintf("T.nilable(%s)", ty)
+}
+
+// pluralize returns the plural of an English word, using the common suffix rules, i.e. `category` will be
+// `categories`, and `address` will be `addresses`
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// singularize returns the singular of an English word, reversing the common suffix rules of pluralize, i.e.
+// `categories` will be `category`, `addresses` will be `address`, and `statuses` will be `status`. As `-ses` is
+// ambiguous, words such as `houses` and `cases` only have their `s` removed. Words which don't end in a plural suffix,
+// such as `status`, are returned as is
+func singularize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "ouses"), strings.HasSuffix(lower, "auses"):
+		return s[:len(s)-1]
+	case strings.HasSuffix(lower, "uses"), strings.HasSuffix(lower, "iases"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return s
+	case strings.HasSuffix(lower, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package openapi
+
+import "testing"
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"pet":      "pets",
+		"category": "categories",
+		"day":      "days",
+		"address":  "addresses",
+		"box":      "boxes",
+		"match":    "matches",
+		"Wish":     "Wishes",
+		"":         "",
+	}
+
+	for word, want := range tests {
+		if got := pluralize(word); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	tests := map[string]string{
+		"tags":       "tag",
+		"categories": "category",
+		"addresses":  "address",
+		"boxes":      "box",
+		"branches":   "branch",
+		"dishes":     "dish",
+		"statuses":   "status",
+		"buses":      "bus",
+		"aliases":    "alias",
+		"houses":     "house",
+		"causes":     "cause",
+		"cases":      "case",
+		"databases":  "database",
+		"keys":       "key",
+		"status":     "status",
+		"analysis":   "analysis",
+		"address":    "address",
+		"data":       "data",
+		"Pets":       "Pet",
+	}
+
+	for plural, want := range tests {
+		if got := singularize(plural); got != want {
+			t.Errorf("singularize(%q) = %q, want %q", plural, got, want)
+		}
+	}
+
+	for _, word := range []string{"tag", "category", "address", "box", "quiz", "branch", "dish", "status", "bus", "virus", "alias", "bias", "house", "cause", "case", "database", "response", "key", "day"} {
+		if got := singularize(pluralize(word)); got != word {
+			t.Errorf("singularize(pluralize(%q)) = %q, want %q", word, got, word)
+		}
+	}
+}
+
+func TestNilable(t *testing.T) {
+	tests := map[string]string{
+		"String":              "T.nilable(String)",
+		"T.nilable(String)":   "T.nilable(String)",
+		"T.untyped":           "T.untyped",
+		"NilClass":            "NilClass",
+		"T::Array[T.untyped]": "T.nilable(T::Array[T.untyped])",
+	}
+
+	for ty, want := range tests {
+		if got := nilable(ty); got != want {
+			t.Errorf("nilable(%q) = %q, want %q", ty, got, want)
+		}
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	path := writeFile(t, "custom.tmpl", `{{ camel .Type.SchemaName }} {{ snake .Type.SchemaName }} {{ pascal .Type.SchemaName }} {{ pluralize .Type.TypeName }}
+{{- range .Type.Properties }} {{ nilable .Type }}{{ end }}`)
+
+	files := generateSpec(t, specWithSchemas("\n    pet_category: {type: object, required: [name], properties: {name: {type: string}}}\n"), Options{Template: path})
+	if got, want := files["pet_category.rb"], "petCategory pet_category PetCategory PetCategories T.nilable(String)"; got != want {
+		t.Errorf("pet_category.rb = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,1003 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	_ "embed"
+
+	"github.com/carlmjohnson/versioninfo"
+	"github.com/iancoleman/strcase"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+//go:embed class.rb.tmpl
+var rawClassTemplate string
+
+//go:embed hash_deserializable.rb.tmpl
+var rawHashDeserializableTemplate string
+
+//go:embed single_file.rb.tmpl
+var rawSingleFileTemplate string
+
+//go:embed fixture.rb.tmpl
+var rawFixtureTemplate string
+
+//go:embed servers.rb.tmpl
+var rawServersTemplate string
+
+//go:embed servers.rbi.tmpl
+var rawServersRBITemplate string
+
+//go:embed servers.rbs.tmpl
+var rawServersRBSTemplate string
+
+//go:embed header.rb.tmpl
+var rawHeaderTemplate string
+
+//go:embed class.rbi.tmpl
+var rawClassRBITemplate string
+
+//go:embed hash_deserializable.rbi.tmpl
+var rawHashDeserializableRBITemplate string
+
+//go:embed single_file.rbi.tmpl
+var rawSingleFileRBITemplate string
+
+//go:embed class.rbs.tmpl
+var rawClassRBSTemplate string
+
+//go:embed hash_deserializable.rbs.tmpl
+var rawHashDeserializableRBSTemplate string
+
+//go:embed single_file.rbs.tmpl
+var rawSingleFileRBSTemplate string
+
+// Generate converts the `#/components/schemas` of an OpenAPI document, or the `#/definitions` of a Swagger 2.0
+// document, into Sorbet types, writing them to the configured output directory. With Paths, the inline schemas of
+// request and response bodies are also converted. The Result is returned even with an error, so the Diagnostics
+// which made a Strict generation fail can be inspected
+func Generate(opts Options) (Result, error) {
+	var result Result
+	err := generate(opts, &result)
+	return result, err
+}
+
+// Result describes what was found while generating the types
+type Result struct {
+	// Diagnostics are the problems found while parsing the schemas, in the order they were found
+	Diagnostics []Diagnostic
+}
+
+// generate converts the schemas, recording the diagnostics in result, and with Check, compares the files against those
+// already generated
+func generate(opts Options, result *Result) error {
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	// each call has its own logger, so concurrent calls with different Options don't interfere
+	log := newLogger(os.Stderr, opts.LogLevel, opts.LogFormat)
+
+	w := &fileWriter{dryRun: opts.DryRun, check: opts.Check, tabs: opts.Indent == IndentTab, log: log}
+	err := writeTypes(opts, w, result)
+	if err != nil {
+		return err
+	}
+
+	// cleaning after generating, rather than before, means unchanged files don't need rewriting
+	if opts.Clean {
+		err = cleanOutput(w, outputDir(opts), opts.OutFileExt)
+		if err != nil {
+			return err
+		}
+	}
+	w.logUnchanged()
+
+	if !opts.Check {
+		return nil
+	}
+	return w.checkResult(outputDir(opts), opts.OutFileExt)
+}
+
+// outputDir returns the directory that the types are generated to, which is nested within Out for each Module
+func outputDir(opts Options) string {
+	dirs := moduleDirs(parseModules(opts.Module))
+	return filepath.Join(append([]string{opts.Out}, dirs...)...)
+}
+
+// writeTypes converts the schemas according to the validated Options, writing the files through w, and recording the
+// diagnostics in result
+func writeTypes(opts Options, w *fileWriter, result *Result) error {
+	path := opts.Path
+	var extraPaths []string
+	if opts.Input == nil {
+		paths, err := expandGlob(opts.Path)
+		if err != nil {
+			return err
+		}
+		path = paths[0]
+		extraPaths = paths[1:]
+	}
+	for _, p := range opts.ExtraPaths {
+		paths, err := expandGlob(p)
+		if err != nil {
+			return err
+		}
+		extraPaths = append(extraPaths, paths...)
+	}
+
+	schemas, info, err := loadSchemas(w.log, path, opts.Input, opts)
+	if err != nil {
+		return err
+	}
+	// documents maps the name of each schema to the path of the document it was read from
+	documents := make(map[string]string, len(schemas))
+	for name := range schemas {
+		documents[name] = path
+	}
+
+	for _, path := range extraPaths {
+		extra, extraInfo, err := loadSchemas(w.log, path, nil, opts)
+		if err != nil {
+			return err
+		}
+		for name := range extra {
+			if _, ok := documents[name]; !ok {
+				documents[name] = path
+			}
+		}
+		for name := range extraInfo.Referenced {
+			info.Referenced[name] = true
+		}
+
+		schemas, err = mergeSchemas(schemas, extra, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	include, err := compilePatterns(opts.Include)
+	if err != nil {
+		return err
+	}
+	exclude, err := compilePatterns(opts.Exclude)
+	if err != nil {
+		return err
+	}
+
+	p := parser{opts: opts, log: w.log}
+
+	if opts.Inflect {
+		p.componentTypes = make(map[string]bool)
+		for name := range schemas {
+			p.componentTypes[p.typeName(name)] = true
+		}
+	}
+
+	if opts.PreferTitle {
+		p.titles = make(map[string]string)
+		for name, sp := range schemas {
+			if s := sp.Schema(); s != nil && s.Title != "" {
+				p.titles[name] = s.Title
+			}
+		}
+	}
+
+	if opts.InterfaceBases {
+		p.interfaceBases = make(map[string]bool)
+		for name := range interfaceBases(schemas) {
+			p.interfaceBases[p.typeName(name)] = true
+		}
+	}
+
+	var allTypes []Type
+
+	// iterate in a consistent order, so output is consistent between runs
+	for _, k := range sortedKeys(schemas) {
+		sp := schemas[k]
+		if (len(include) > 0 && !matchesAny(include, k)) || matchesAny(exclude, k) {
+			w.log.debugf("Skipping %s as filtered", k)
+			continue
+		}
+
+		if isSchemaRef(sp) {
+			allTypes = append(allTypes, p.parseRefAlias(k, sp.GetReference()))
+			continue
+		}
+
+		schema := sp.Schema()
+		if schema != nil && p.skipExtension(k, schema) {
+			continue
+		}
+
+		p.document = documents[k]
+		types := p.parseComponent(k, schema)
+		if len(types) == 0 {
+			w.log.warnf("Missing type data for schema %s", k)
+		}
+		allTypes = append(allTypes, types...)
+	}
+
+	p.applyDiscriminators(allTypes)
+
+	if opts.AliasStyle == AliasStyleWrapper {
+		for i := range allTypes {
+			allTypes[i].IsWrapper = allTypes[i].IsAlias() && !allTypes[i].IsRefAlias
+		}
+	}
+
+	resolveRefAliases(allTypes)
+
+	if opts.HoistUnions {
+		allTypes = p.hoistUnions(allTypes)
+	}
+
+	result.Diagnostics = p.diagnostics
+	if len(p.diagnostics) > 0 {
+		w.log.warnf("Parsed with diagnostics: %s", summarizeDiagnostics(p.diagnostics))
+
+		if opts.Strict {
+			return fmt.Errorf("%d diagnostics were reported while parsing, which are errors as Strict is enabled", len(p.diagnostics))
+		}
+	}
+
+	for i := range allTypes {
+		allTypes[i].RelativeRequires = p.relativeRequires(allTypes[i])
+	}
+
+	if opts.ReportUnused {
+		reportUnused(w.log, allTypes, info.Referenced)
+	}
+
+	templates, err := parseTemplates(rbsAliases(allTypes))
+	if err != nil {
+		return err
+	}
+
+	if opts.Template != "" {
+		err = parseCustomTemplate(templates, "class."+opts.Format+".tmpl", opts.Template)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = validateNames(allTypes, opts.SingleFile != "")
+	if err != nil {
+		return err
+	}
+
+	modules := parseModules(opts.Module)
+	dirs := moduleDirs(modules)
+
+	if opts.Zeitwerk {
+		err = validateZeitwerk(modules, dirs, allTypes)
+		if err != nil {
+			return err
+		}
+	}
+
+	outPath := outputDir(opts)
+
+	if opts.Out != OutStdout {
+		err = w.mkdirAll(outPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	metadata := Metadata{
+		Command: "openapi-sorbet",
+		Version: Version(),
+
+		Modules:       modules,
+		ModuleStyle:   opts.ModuleStyle,
+		RequireSuffix: strings.TrimSuffix(opts.OutFileExt, ".rb"),
+
+		TypedSigil:          opts.TypedSigil,
+		FrozenStringLiteral: !opts.NoFrozenStringLiteral,
+	}
+	metadata.Spec.Title = info.Title
+	metadata.Spec.Version = info.Version
+	metadata.Spec.Description = prepareComment(info.Description)
+	if info.Contact != nil {
+		metadata.Spec.Contact = joinNonEmpty(info.Contact.Name, angleBracketed(info.Contact.Email), info.Contact.URL)
+	}
+	if info.License != nil {
+		metadata.Spec.License = joinNonEmpty(info.License.Name, info.License.URL)
+	}
+	if !opts.NoTimestamp {
+		metadata.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	// files that aren't rendered from a template, such as types.rb, still need the same header
+	var header strings.Builder
+	err = templates.ExecuteTemplate(&header, "magic_comments", metadata)
+	if err != nil {
+		return err
+	}
+	header.WriteString("\n\n")
+	err = templates.ExecuteTemplate(&header, "header", metadata)
+	if err != nil {
+		return err
+	}
+
+	err = writeManifest(w, opts, modules, dirs, allTypes)
+	if err != nil {
+		return err
+	}
+
+	err = writeFixtures(w, opts, templates, metadata, outPath, allTypes, schemaExamples(schemas))
+	if err != nil {
+		return err
+	}
+
+	serversFile, err := writeServers(w, opts, templates, metadata, outPath, info.Servers, allTypes)
+	if err != nil {
+		return err
+	}
+
+	if opts.SingleFile != "" {
+		data := struct {
+			Metadata Metadata
+			Types    []Type
+		}{
+			Metadata: metadata,
+			Types:    dependencyOrder(allTypes),
+		}
+
+		if opts.Out == OutStdout {
+			// only the generated code is written to stdout, as logs are written to stderr, so it can be piped
+			var sb strings.Builder
+			err = templates.ExecuteTemplate(&sb, "single_file."+opts.Format+".tmpl", data)
+			if err != nil {
+				return fmt.Errorf("failed to render to stdout: %w", err)
+			}
+			_, err = os.Stdout.WriteString(w.indented(sb.String()))
+			return err
+		}
+
+		err = renderFile(w, filepath.Join(outPath, opts.SingleFile), templates, "single_file."+opts.Format+".tmpl", data)
+		if err != nil {
+			return err
+		}
+
+		w.generated("Generated %s with all types", opts.SingleFile)
+
+		return writeIndex(w, opts, header.String(), dirs, nonEmpty(strings.TrimSuffix(opts.SingleFile, ".rb"), serversFile))
+	}
+
+	err = renderTypes(w, opts.Jobs, outPath, opts.Format, opts.OutFileExt, templates, metadata, allTypes)
+	if err != nil && opts.Template != "" {
+		return fmt.Errorf("failed to render the template %s: %w\nThe available fields are: %s", opts.Template, err, strings.Join(templateFields(), ", "))
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Format != FormatRB {
+		// RBI and RBS files are read by the type checker rather than loaded, so don't need requiring
+		err = renderFile(w, filepath.Join(outPath, "hash_deserializable."+opts.Format), templates, "hash_deserializable."+opts.Format+".tmpl", struct{ Metadata Metadata }{metadata})
+		if err != nil {
+			return err
+		}
+
+		w.generated("Generated hash_deserializable.%s", opts.Format)
+
+		return nil
+	}
+
+	// Create types.rb file
+	var typesFile strings.Builder
+	fmt.Fprintf(&typesFile, "%s\n\n", header.String())
+
+	// Write requires for all generated type files
+	sortedTypes := make([]Type, len(allTypes))
+	copy(sortedTypes, allTypes)
+	slices.SortFunc(sortedTypes, func(a, b Type) bool {
+		return a.Filename < b.Filename
+	})
+	for _, t := range sortedTypes {
+		fmt.Fprintf(&typesFile, "require_relative '%s'\n", t.Filename+metadata.RequireSuffix)
+	}
+	if serversFile != "" {
+		fmt.Fprintf(&typesFile, "require_relative '%s'\n", serversFile)
+	}
+
+	err = w.writeFile(filepath.Join(outPath, "types.rb"), []byte(typesFile.String()))
+	if err != nil {
+		return err
+	}
+
+	w.generated("Generated types.rb with all type requires")
+
+	// Render hash_deserializable template
+	toplevelData := struct {
+		Metadata Metadata
+	}{
+		Metadata: metadata,
+	}
+	err = renderFile(w, filepath.Join(outPath, "hash_deserializable.rb"), templates, "hash_deserializable.rb.tmpl", toplevelData)
+	if err != nil {
+		return err
+	}
+
+	w.generated("Generated hash_deserializable.rb")
+
+	filenames := nonEmpty(serversFile)
+	for _, t := range allTypes {
+		filenames = append(filenames, t.Filename+metadata.RequireSuffix)
+	}
+
+	return writeIndex(w, opts, header.String(), dirs, filenames)
+}
+
+// renderTypes renders each type to its own file of the given format, with the given extension, using the given number
+// of concurrent workers. All types are rendered, even if some fail, with the errors returned in the same order as the
+// types
+func renderTypes(w *fileWriter, jobs int, outPath string, format string, ext string, templates *template.Template, metadata Metadata, types []Type) error {
+	errs := make([]error, len(types))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				data := struct {
+					Metadata Metadata
+					Type     Type
+				}{
+					Metadata: metadata,
+					Type:     types[i],
+				}
+
+				errs[i] = renderFile(w, filepath.Join(outPath, types[i].Filename)+ext, templates, "class."+format+".tmpl", data)
+			}
+		}()
+	}
+
+	for i := range types {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// writeIndex writes the Index file to the root of the output directory, requiring each of the given files within the
+// modules' directories, if an Index file is configured
+func writeIndex(w *fileWriter, opts Options, header string, dirs []string, filenames []string) error {
+	if opts.Index == "" {
+		return nil
+	}
+
+	var requires []string
+	for _, f := range filenames {
+		requires = append(requires, path.Join(append(dirs, f)...))
+	}
+	slices.Sort(requires)
+
+	var sb strings.Builder
+	sb.WriteString(header + "\n\n")
+	for _, r := range requires {
+		fmt.Fprintf(&sb, "require_relative '%s'\n", r)
+	}
+
+	err := w.writeFile(filepath.Join(opts.Out, opts.Index), []byte(sb.String()))
+	if err != nil {
+		return err
+	}
+
+	w.generated("Generated %s with all type requires", opts.Index)
+
+	return nil
+}
+
+// parseTemplates parses all templates, for `.rb`, `.rbi` and `.rbs` files, into a single set, so they can share the
+// `type` and `hash_deserializable` definitions. The RBS names of type aliases are needed to convert Sorbet types to RBS
+func parseTemplates(rbsAliases map[string]string) (*template.Template, error) {
+	tmpl := template.New("")
+	tmpl.Funcs(template.FuncMap{
+		// include renders the named template to a string, so it can be piped to other functions, such as indent
+		"include": func(name string, data any) (string, error) {
+			var sb strings.Builder
+			err := tmpl.ExecuteTemplate(&sb, name, data)
+			return sb.String(), err
+		},
+		// indent indents each line of text by the given depth, of two spaces each
+		"indent": indent,
+		// camel converts a name to lowerCamelCase, i.e. `user_id` will be `userId`
+		"camel": strcase.ToLowerCamel,
+		// snake converts a name to snake_case, i.e. `UserId` will be `user_id`
+		"snake": strcase.ToSnake,
+		// pascal converts a name to PascalCase, i.e. `user_id` will be `UserId`
+		"pascal": strcase.ToCamel,
+		// pluralize returns the plural of an English word, i.e. `category` will be `categories`
+		"pluralize": pluralize,
+		// nilable wraps a Sorbet type in `T.nilable`, unless it can already be `nil`
+		"nilable": nilable,
+		// comment renders text as Ruby comment lines
+		"comment": rubyComment,
+		// rbs converts a Sorbet type to RBS
+		"rbs": func(ty string) string {
+			return rbsType(ty, rbsAliases)
+		},
+	})
+
+	for _, t := range []struct{ name, raw string }{
+		{"class.rb.tmpl", rawClassTemplate},
+		{"hash_deserializable.rb.tmpl", rawHashDeserializableTemplate},
+		{"single_file.rb.tmpl", rawSingleFileTemplate},
+		{"header.rb.tmpl", rawHeaderTemplate},
+		{"fixture.rb.tmpl", rawFixtureTemplate},
+		{"servers.rb.tmpl", rawServersTemplate},
+		{"servers.rbi.tmpl", rawServersRBITemplate},
+		{"servers.rbs.tmpl", rawServersRBSTemplate},
+		{"class.rbi.tmpl", rawClassRBITemplate},
+		{"hash_deserializable.rbi.tmpl", rawHashDeserializableRBITemplate},
+		{"single_file.rbi.tmpl", rawSingleFileRBITemplate},
+		{"class.rbs.tmpl", rawClassRBSTemplate},
+		{"hash_deserializable.rbs.tmpl", rawHashDeserializableRBSTemplate},
+		{"single_file.rbs.tmpl", rawSingleFileRBSTemplate},
+	} {
+		_, err := tmpl.New(t.name).Parse(t.raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tmpl, nil
+}
+
+// parseCustomTemplate parses the template file at path in place of the named template, so it's rendered with the same
+// data. As it's parsed into the same set, it can use the other templates, and redefine them, such as `type`
+func parseCustomTemplate(tmpl *template.Template, name string, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the template %s: %w", path, err)
+	}
+
+	_, err = tmpl.New(name).Parse(string(raw))
+	if err != nil {
+		return fmt.Errorf("failed to parse the template %s: %w\nThe available fields are: %s", path, err, strings.Join(templateFields(), ", "))
+	}
+
+	return nil
+}
+
+// templateFields returns the fields, and methods, that the class template can use, i.e. `.Type.TypeName`
+func templateFields() []string {
+	var fields []string
+	for _, v := range []struct {
+		prefix string
+		value  any
+	}{
+		{".Metadata", Metadata{}},
+		{".Type", Type{}},
+	} {
+		t := reflect.TypeOf(v.value)
+		for i := 0; i < t.NumField(); i++ {
+			fields = append(fields, v.prefix+"."+t.Field(i).Name)
+		}
+		for i := 0; i < t.NumMethod(); i++ {
+			fields = append(fields, v.prefix+"."+t.Method(i).Name)
+		}
+	}
+	return fields
+}
+
+// documentInfo contains the details of a document, other than its schemas, which are used in the generated files
+type documentInfo struct {
+	*base.Info
+	// Servers contains the document's `servers`, or for Swagger 2.0 documents, those of its `host` and `basePath`
+	Servers []server
+	// Referenced contains the names of the schemas that are used other than by another schema, such as through a
+	// `$ref` from an operation's response, or by being generated from an operation's body with Paths
+	Referenced map[string]bool
+}
+
+// server is a URL that the API is served from
+type server struct {
+	URL         string
+	Description string
+}
+
+// loadSchemas reads and parses the OpenAPI document at the path, unless its contents are already provided as input,
+// returning the schemas to generate types for and the document's `info` and `servers`
+func loadSchemas(log *logger, path string, input []byte, opts Options) (map[string]*base.SchemaProxy, *documentInfo, error) {
+	docBytes := input
+	if docBytes == nil {
+		var err error
+		docBytes, err = readDocument(path, opts.Timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// libopenapi sniffs whether the document is JSON or YAML from its contents, so there's no need to rely on the file
+	// extension. As JSON is a subset of YAML, both are parsed by the same YAML parser
+	document, err := libopenapi.NewDocument(docBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s as a JSON or YAML OpenAPI document: %w", path, err)
+	}
+
+	return buildSchemas(log, document, path, opts)
+}
+
+// expandGlob returns the files matching a path containing a glob pattern, i.e. `specs/*.yaml`, in sorted order. Paths
+// without a pattern, such as `-` for stdin or HTTP(S) URLs, are returned as is
+func expandGlob(path string) ([]string, error) {
+	if !strings.ContainsAny(path, "*?[") || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return []string{path}, nil
+	}
+
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %s: %w", path, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", path)
+	}
+
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// mergeSchemas merges the schemas read from another document at path into schemas, returning a new map. Schemas
+// that are defined identically in both are deduplicated, but differing definitions of the same name are an error
+func mergeSchemas(schemas map[string]*base.SchemaProxy, other map[string]*base.SchemaProxy, path string) (map[string]*base.SchemaProxy, error) {
+	merged := make(map[string]*base.SchemaProxy, len(schemas)+len(other))
+	for k, v := range schemas {
+		merged[k] = v
+	}
+
+	var errs []error
+	for _, k := range sortedKeys(other) {
+		existing, ok := merged[k]
+		if !ok {
+			merged[k] = other[k]
+			continue
+		}
+
+		if !sameSchema(existing, other[k]) {
+			errs = append(errs, fmt.Errorf("schema %s in %s conflicts with an existing definition of the same name", k, path))
+		}
+	}
+
+	return merged, errors.Join(errs...)
+}
+
+// sameSchema reports whether two schemas are defined identically, regardless of where they're defined. Schemas that
+// are generated, rather than read from a document, such as those from responseSchemas, are compared by their contents
+func sameSchema(a *base.SchemaProxy, b *base.SchemaProxy) bool {
+	if a.GoLow() == nil || b.GoLow() == nil {
+		return reflect.DeepEqual(a.Schema(), b.Schema())
+	}
+	return a.GoLow().Hash() == b.GoLow().Hash()
+}
+
+// buildSchemas builds the model of the document, returning the schemas to generate types for and the document's `info`.
+// Swagger 2.0 documents' `#/definitions` are equivalent to OpenAPI 3's `#/components/schemas`
+func buildSchemas(log *logger, document libopenapi.Document, path string, opts Options) (map[string]*base.SchemaProxy, *documentInfo, error) {
+	if document.GetSpecInfo().SpecFormat == datamodel.OAS2 {
+		d, errs := document.BuildV2Model()
+		if d == nil {
+			return nil, nil, fmt.Errorf("failed to build Swagger 2.0 model for %s: %w", path, errors.Join(errs...))
+		}
+		logModelWarnings(log, errs)
+
+		if opts.Paths || opts.ResponsesEnum {
+			log.warnf("Generating types from the operations under paths is not supported for Swagger 2.0 documents")
+		}
+
+		info := &documentInfo{Info: d.Model.Info, Referenced: externalRefs(document.GetSpecInfo().RootNode)}
+		if d.Model.Host != "" {
+			schemes := d.Model.Schemes
+			if len(schemes) == 0 {
+				schemes = []string{"https"}
+			}
+			for _, scheme := range schemes {
+				info.Servers = append(info.Servers, server{URL: scheme + "://" + d.Model.Host + d.Model.BasePath})
+			}
+		}
+
+		if d.Model.Definitions == nil {
+			return nil, info, nil
+		}
+		return d.Model.Definitions.Definitions, info, nil
+	}
+
+	d, errs := document.BuildV3Model()
+	if d == nil {
+		return nil, nil, fmt.Errorf("failed to build OpenAPI v3 model for %s: %w", path, errors.Join(errs...))
+	}
+	logModelWarnings(log, errs)
+
+	info := &documentInfo{Info: d.Model.Info, Referenced: externalRefs(document.GetSpecInfo().RootNode)}
+
+	var schemas map[string]*base.SchemaProxy
+	if d.Model.Components != nil {
+		schemas = d.Model.Components.Schemas
+	}
+	if opts.Paths {
+		bodies := pathSchemas(log, d.Model.Paths)
+		for name := range bodies {
+			info.Referenced[name] = true
+		}
+		schemas = mergePathSchemas(log, schemas, bodies)
+	}
+	if opts.ResponsesEnum {
+		responses := responseSchemas(d.Model.Paths)
+		for name := range responses {
+			info.Referenced[name] = true
+		}
+		schemas = mergePathSchemas(log, schemas, responses)
+	}
+
+	for _, s := range d.Model.Servers {
+		info.Servers = append(info.Servers, server{URL: s.URL, Description: s.Description})
+	}
+
+	return schemas, info, nil
+}
+
+// nonEmpty returns the values which aren't empty
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// joinNonEmpty joins the values which aren't empty with a space
+func joinNonEmpty(values ...string) string {
+	return strings.Join(nonEmpty(values...), " ")
+}
+
+// angleBracketed wraps an email address in angle brackets, as it's conventionally written after a name, if set
+func angleBracketed(email string) string {
+	if email == "" {
+		return ""
+	}
+	return "<" + email + ">"
+}
+
+// logModelWarnings logs the errors returned when building a model, as a model is still built when there are circular
+// references, which the parser handles
+func logModelWarnings(log *logger, errs []error) {
+	for _, err := range errs {
+		log.warnf("%v", err)
+	}
+}
+
+// generatedMarker is contained in the header of generated files, so they can be distinguished from hand-written files
+const generatedMarker = "Generated from OpenAPI specification"
+
+// cleanOutput removes any previously generated `.rb`, `.rbi`, `.rbs` or ext files from the module's directory that weren't
+// written by this generation, so types for removed or renamed schemas don't linger. Only files containing the
+// generatedMarker are removed, and subdirectories are left alone, as they may contain the files of other modules
+// generated to the same output directory
+func cleanOutput(w *fileWriter, dir string, ext string) error {
+	files, err := generatedFiles(dir, ext)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		if w.written[path] {
+			continue
+		}
+		err = w.remove(path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatedFiles returns the previously generated `.rb`, `.rbi`, `.rbs` or ext files within the directory, which contain
+// the generatedMarker. Subdirectories are skipped, as they may contain the files of other modules generated to the same
+// output directory
+func generatedFiles(dir string, ext string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !(slices.Contains([]string{".rb", ".rbi", ".rbs"}, filepath.Ext(entry.Name())) || strings.HasSuffix(entry.Name(), ext)) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(string(contents), generatedMarker) {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// compilePatterns compiles each of the regular expressions
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %#v: %w", pattern, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// matchesAny reports whether the name matches any of the regular expressions
+func matchesAny(res []*regexp.Regexp, name string) bool {
+	for _, re := range res {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFile executes the named template with the given data, writing the result to the file at path
+func renderFile(w *fileWriter, path string, tmpl *template.Template, name string, data any) error {
+	var sb strings.Builder
+	err := tmpl.ExecuteTemplate(&sb, name, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", path, err)
+	}
+
+	return w.writeFile(path, []byte(w.indented(sb.String())))
+}
+
+// indent indents each line of s by the given depth, except for the `=begin` and `=end` of block comments, which must be
+// at the start of a line
+func indent(depth int, s string) string {
+	prefix := strings.Repeat("  ", depth)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" || strings.HasPrefix(line, "=begin") || strings.HasPrefix(line, "=end") {
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dependencyOrder returns the types sorted so that each type is preceded by the types it references, as they are all
+// defined in the same file, and Ruby requires constants to be defined before they are used
+func dependencyOrder(types []Type) []Type {
+	byRequire := make(map[string]Type, len(types))
+	for _, t := range types {
+		byRequire["./"+t.Filename] = t
+	}
+
+	sorted := make([]Type, len(types))
+	copy(sorted, types)
+	slices.SortFunc(sorted, func(a, b Type) bool {
+		return a.Filename < b.Filename
+	})
+
+	var ordered []Type
+	seen := make(map[string]bool, len(types))
+	var visit func(t Type)
+	visit = func(t Type) {
+		if seen[t.Filename] {
+			return
+		}
+		seen[t.Filename] = true
+
+		for _, r := range t.RelativeRequires {
+			if dep, ok := byRequire[r]; ok {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, t)
+	}
+
+	for _, t := range sorted {
+		visit(t)
+	}
+
+	return ordered
+}
+
+// sortedKeys returns the keys of the map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
+
+func parseModules(module string) []string {
+	modules := strings.Split(module, "::")
+	if len(modules) == 1 && modules[0] == "" {
+		modules = nil
+	}
+
+	return modules
+}
+
+// moduleDirs returns the directory for each of the modules, which the generated files are nested within
+func moduleDirs(modules []string) []string {
+	var dirs []string
+	for _, m := range modules {
+		dirs = append(dirs, strcase.ToSnake(m))
+	}
+	return dirs
+}
+
+// Version returns the version of openapi-sorbet, from the build information, or `(unknown)` if it isn't available
+func Version() string {
+	version := versioninfo.Short()
+	if version == "" {
+		version = "(unknown)"
+	}
+	return version
+}
+
+// readDocument reads the OpenAPI document from the given path, from stdin if the path is `-`, or fetches it if the path
+// is an HTTP(S) URL
+func readDocument(path string, timeout time.Duration) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return fetchDocument(path, timeout)
+	}
+
+	return os.ReadFile(path)
+}
+
+// fetchDocument retrieves the OpenAPI document from the given URL
+func fetchDocument(url string, timeout time.Duration) ([]byte, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	// avoid passing i.e. an HTML error page to the parser
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch %s: received HTTP status %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,1337 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// generateSpec runs Generate against an inline document, returning the contents of each generated file by its path
+// relative to the output directory. An empty spec leaves Input unset, for the document to be read from opts.Path
+func generateSpec(t testing.TB, spec string, opts Options) map[string]string {
+	t.Helper()
+
+	files, _ := generateSpecLogs(t, spec, opts)
+	return files
+}
+
+// generateSpecLogs runs Generate in the same way as generateSpec, also returning what was logged
+func generateSpecLogs(t testing.TB, spec string, opts Options) (map[string]string, string) {
+	t.Helper()
+
+	if spec != "" {
+		opts.Input = []byte(spec)
+	}
+	if opts.Out == "" {
+		opts.Out = t.TempDir()
+	}
+	opts.NoTimestamp = true
+
+	var err error
+	logs := capture(t, &os.Stderr, func() {
+		_, err = Generate(opts)
+	})
+	if err != nil {
+		t.Fatalf("Generate() returned an error: %v", err)
+	}
+	return readFiles(t, opts.Out), logs
+}
+
+// readFiles returns the contents of each file within the directory, by its path relative to the directory
+func readFiles(t testing.TB, dir string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(contents)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read the generated files: %v", err)
+	}
+	return files
+}
+
+// writeFile writes the contents to a file of the given name in a temporary directory, returning its path
+func writeFile(t testing.TB, name string, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// specWithSchemas returns an OpenAPI document with the given YAML, indented by four spaces, as its schemas
+func specWithSchemas(schemas string) string {
+	return `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  schemas:` + schemas
+}
+
+// assertContains fails the test if the generated file doesn't contain each of want
+func assertContains(t testing.TB, files map[string]string, file string, want ...string) {
+	t.Helper()
+
+	got, ok := files[file]
+	if !ok {
+		t.Fatalf("%s wasn't generated", file)
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("%s doesn't contain %q:\n%s", file, w, got)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	spec := specWithSchemas(`
+    Zebra:
+      type: object
+      properties:
+        stripes: {type: integer}
+        name: {type: string}
+        owner: {$ref: '#/components/schemas/Owner'}
+    Owner:
+      type: object
+      required: [name]
+      properties:
+        pets:
+          type: array
+          items: {$ref: '#/components/schemas/Zebra'}
+        name: {type: string}
+        address:
+          type: object
+          properties:
+            street: {type: string}
+            city: {type: string}
+    Status:
+      type: string
+      enum: [inactive, active, pending]
+    Animal:
+      oneOf:
+        - $ref: '#/components/schemas/Zebra'
+        - type: object
+          properties:
+            legs: {type: integer}
+`)
+
+	want := generateSpec(t, spec, Options{})
+	for i := 0; i < 5; i++ {
+		if got := generateSpec(t, spec, Options{}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("the output differed between runs:\n%v\nwant:\n%v", got, want)
+		}
+	}
+}
+
+func TestYAMLAndJSON(t *testing.T) {
+	yamlSpec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        tags: {type: array, items: {type: string}}
+`)
+	jsonSpec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "tags": {"type": "array", "items": {"type": "string"}}
+        }
+      }
+    }
+  }
+}`
+
+	// an empty spec leaves Input unset, so the document is instead read from Path
+	jsonPath := filepath.Join(t.TempDir(), "openapi.json")
+	if err := os.WriteFile(jsonPath, []byte(jsonSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := generateSpec(t, yamlSpec, Options{})
+	got := generateSpec(t, "", Options{Path: jsonPath})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the JSON document generated:\n%v\nwant the same as the YAML document:\n%v", got, want)
+	}
+	assertContains(t, got, "pet.rb", "const :name, String", "const :tags, T.nilable(T::Array[String])")
+}
+
+func TestStdin(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.WriteString(spec); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	defer func(original *os.File) { os.Stdin = original }(os.Stdin)
+	os.Stdin = stdin
+
+	files := generateSpec(t, "", Options{Path: "-"})
+
+	assertContains(t, files, "pet.rb", "const :name, T.nilable(String)")
+	assertContains(t, files, "types.rb", "require_relative 'pet'")
+}
+
+func TestFetchDocument(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openapi.yaml":
+			_, _ = io.WriteString(w, spec)
+		case "/slow.yaml":
+			time.Sleep(100 * time.Millisecond)
+			_, _ = io.WriteString(w, spec)
+		default:
+			http.Error(w, "<html>Not Found</html>", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("generates", func(t *testing.T) {
+		files := generateSpec(t, "", Options{Path: server.URL + "/openapi.yaml"})
+
+		assertContains(t, files, "pet.rb", "const :name, T.nilable(String)")
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		timeout time.Duration
+		wantErr string
+	}{
+		{
+			name:    "not found",
+			path:    "/missing.yaml",
+			timeout: time.Second,
+			wantErr: "received HTTP status 404 Not Found",
+		},
+		{
+			name:    "timeout",
+			path:    "/slow.yaml",
+			timeout: 10 * time.Millisecond,
+			wantErr: "Timeout exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readDocument(server.URL+tt.path, tt.timeout)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readDocument() returned the error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "invalid enum style",
+			opts: Options{Input: []byte(specWithSchemas(" {}")), EnumStyle: "bogus"},
+			want: `invalid EnumStyle "bogus"`,
+		},
+		{
+			name: "invalid allOf style",
+			opts: Options{Input: []byte(specWithSchemas(" {}")), AllOfStyle: "bogus"},
+			want: `invalid AllOfStyle "bogus"`,
+		},
+		{
+			name: "Zeitwerk with a single file",
+			opts: Options{Input: []byte(specWithSchemas(" {}")), Zeitwerk: true, SingleFile: "types.rb"},
+			want: "Zeitwerk validation cannot be used with SingleFile",
+		},
+		{
+			name: "invalid typed sigil",
+			opts: Options{Input: []byte(specWithSchemas(" {}")), TypedSigil: "loose"},
+			want: `invalid TypedSigil "loose"`,
+		},
+		{
+			name: "invalid class case",
+			opts: Options{Input: []byte(specWithSchemas(" {}")), ClassCase: "kebab"},
+			want: `invalid ClassCase "kebab"`,
+		},
+		{
+			name: "RBI with an index",
+			opts: Options{Input: []byte(specWithSchemas(" {}")), Format: FormatRBI, Index: "all.rb"},
+			want: "an Index cannot be used with the rbi Format",
+		},
+		{
+			name: "no document",
+			want: "no OpenAPI document was provided",
+		},
+		{
+			name: "missing file",
+			opts: Options{Path: "does-not-exist.yaml"},
+			want: "does-not-exist.yaml",
+		},
+		{
+			name: "invalid YAML",
+			opts: Options{Input: []byte("openapi: [")},
+			want: "did not find expected node content",
+		},
+		{
+			name: "not an OpenAPI document",
+			opts: Options{Input: []byte("hello: world")},
+			want: "spec type not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Out = t.TempDir()
+
+			_, err := Generate(tt.opts)
+			if err == nil {
+				t.Fatal("Generate() didn't return an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() returned the error %q, want it to contain %q", err, tt.want)
+			}
+			if files := readFiles(t, tt.opts.Out); len(files) > 0 {
+				t.Errorf("Generate() wrote files despite the error: %v", files)
+			}
+		})
+	}
+}
+
+func TestSingleFile(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Owner:
+      type: object
+      properties:
+        pet: {$ref: '#/components/schemas/Pet'}
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+    Status:
+      type: string
+      enum: [active]
+`), Options{SingleFile: "types.rb"})
+
+	if len(files) != 1 {
+		t.Fatalf("generated %v, want only types.rb", sortedKeys(files))
+	}
+	assertContains(t, files, "types.rb", "class Owner", "class Pet", "class Status < T::Enum")
+
+	// a type must be defined before the types that reference it
+	if owner, pet := strings.Index(files["types.rb"], "class Owner"), strings.Index(files["types.rb"], "class Pet"); pet > owner {
+		t.Errorf("Pet is defined after Owner, which references it:\n%s", files["types.rb"])
+	}
+	if got := strings.Count(files["types.rb"], "module HashDeserializable"); got != 1 {
+		t.Errorf("types.rb defines HashDeserializable %d times, want once:\n%s", got, files["types.rb"])
+	}
+}
+
+func TestIndex(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet: {type: object, properties: {name: {type: string}}}
+    Owner: {type: object, properties: {pet: {$ref: '#/components/schemas/Pet'}}}
+`)
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "file per type",
+			opts: Options{Index: "all.rb"},
+			want: "require_relative 'owner'\nrequire_relative 'pet'\n",
+		},
+		{
+			name: "nested modules",
+			opts: Options{Index: "all.rb", Module: "Api::PetStore"},
+			want: "require_relative 'api/pet_store/owner'\nrequire_relative 'api/pet_store/pet'\n",
+		},
+		{
+			name: "single file",
+			opts: Options{Index: "all.rb", Module: "Api", SingleFile: "types.rb"},
+			want: "require_relative 'api/types'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, spec, tt.opts)
+
+			if got := files["all.rb"]; !strings.HasSuffix(got, "=end\n\n"+tt.want) {
+				t.Errorf("all.rb contains:\n%s\nwant the header, followed by:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+
+		if _, ok := files["all.rb"]; ok {
+			t.Error("all.rb was generated without an Index")
+		}
+	})
+}
+
+func TestSwagger2(t *testing.T) {
+	files := generateSpec(t, `
+swagger: "2.0"
+info: {title: Legacy, version: "2"}
+paths: {}
+definitions:
+  Pet:
+    type: object
+    required: [name]
+    properties:
+      name: {type: string}
+      owner: {$ref: '#/definitions/Owner'}
+  Owner:
+    type: object
+    properties:
+      id: {type: integer}
+`, Options{})
+
+	assertContains(t, files, "pet.rb", "Legacy 2", "class Pet", "const :name, String", "const :owner, T.nilable(Owner)", "require_relative './owner'")
+	assertContains(t, files, "owner.rb", "class Owner", "const :id, T.nilable(Integer)")
+}
+
+func TestModules(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`)
+
+	tests := []struct {
+		name   string
+		module string
+		file   string
+		want   string
+	}{
+		{
+			name: "none",
+			file: "pet.rb",
+			want: `=begin
+Pet 
+=end
+class Pet < T::Struct
+  include HashDeserializable
+
+  const :name, T.nilable(String)
+end`,
+		},
+		{
+			name:   "one",
+			module: "Petstore",
+			file:   "petstore/pet.rb",
+			want: `module Petstore
+=begin
+  Pet 
+=end
+  class Pet < T::Struct
+    include HashDeserializable
+
+    const :name, T.nilable(String)
+  end
+end`,
+		},
+		{
+			name:   "two",
+			module: "ExternalClients::Petstore",
+			file:   "external_clients/petstore/pet.rb",
+			want: `module ExternalClients
+  module Petstore
+=begin
+    Pet 
+=end
+    class Pet < T::Struct
+      include HashDeserializable
+
+      const :name, T.nilable(String)
+    end
+  end
+end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, spec, Options{Module: tt.module})
+
+			got := files[tt.file]
+			if !strings.HasSuffix(strings.TrimRight(got, "\n"), "\n"+tt.want) {
+				t.Errorf("%s doesn't end with:\n%s\ngot:\n%s", tt.file, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestModuleStyle(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`)
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{
+			style: ModuleStyleNested,
+			want: `module ExternalClients
+  module Petstore
+=begin
+    Pet 
+=end
+    class Pet < T::Struct
+      include HashDeserializable
+
+      const :name, T.nilable(String)
+    end
+  end
+end`,
+		},
+		{
+			style: ModuleStyleCompact,
+			want: `module ExternalClients::Petstore
+=begin
+  Pet 
+=end
+  class Pet < T::Struct
+    include HashDeserializable
+
+    const :name, T.nilable(String)
+  end
+end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			files := generateSpec(t, spec, Options{Module: "ExternalClients::Petstore", ModuleStyle: tt.style})
+
+			got := files["external_clients/petstore/pet.rb"]
+			if !strings.HasSuffix(strings.TrimRight(got, "\n"), "\n"+tt.want) {
+				t.Errorf("pet.rb doesn't end with:\n%s\ngot:\n%s", tt.want, got)
+			}
+		})
+	}
+
+	_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), ModuleStyle: "flat"})
+	if want := `invalid ModuleStyle "flat"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+	}
+}
+
+func TestIndent(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      description: A pet
+      properties:
+        name: {type: string}
+`)
+
+	files := generateSpec(t, spec, Options{Module: "Petstore", Indent: IndentTab})
+	want := "module Petstore\n=begin\n  Pet A pet\n=end\n\tclass Pet < T::Struct\n\t\tinclude HashDeserializable\n\n\t\tconst :name, T.nilable(String)\n\tend\nend"
+	if got := files["petstore/pet.rb"]; !strings.HasSuffix(strings.TrimRight(got, "\n"), "\n"+want) {
+		t.Errorf("pet.rb doesn't end with:\n%s\ngot:\n%s", want, got)
+	}
+
+	_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), Indent: "4"})
+	if want := `invalid Indent "4"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+	}
+}
+
+func TestIncludeAndExclude(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet: {type: object, properties: {name: {type: string}}}
+    PetStatus: {type: string, enum: [available, sold]}
+    Order: {type: object, properties: {id: {type: integer}}}
+    OrderStatus: {type: string}
+    User: {type: object, properties: {id: {type: integer}}}
+`)
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "all",
+			want: []string{"order.rb", "order_status.rb", "pet.rb", "pet_status.rb", "user.rb"},
+		},
+		{
+			name: "include",
+			opts: Options{Include: []string{"^Pet"}},
+			want: []string{"pet.rb", "pet_status.rb"},
+		},
+		{
+			name: "multiple includes",
+			opts: Options{Include: []string{"^Pet$", "^User$"}},
+			want: []string{"pet.rb", "user.rb"},
+		},
+		{
+			name: "exclude",
+			opts: Options{Exclude: []string{"Status$"}},
+			want: []string{"order.rb", "pet.rb", "user.rb"},
+		},
+		{
+			name: "include and exclude",
+			opts: Options{Include: []string{"^Pet", "^Order"}, Exclude: []string{"Status$"}},
+			want: []string{"order.rb", "pet.rb"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, spec, tt.opts)
+
+			var got []string
+			for _, file := range sortedKeys(files) {
+				if file != "types.rb" && file != "hash_deserializable.rb" {
+					got = append(got, file)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generated %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), Include: []string{"("}})
+		if want := `invalid pattern "("`; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+		}
+	})
+}
+
+func TestClean(t *testing.T) {
+	out := t.TempDir()
+	write := func(path, contents string) {
+		t.Helper()
+		path = filepath.Join(out, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := func(schema string) string {
+		return specWithSchemas("\n    " + schema + ": {type: object, properties: {name: {type: string}}}\n")
+	}
+
+	// generate sibling and nested modules to the same output directory
+	generateSpec(t, spec("Pet"), Options{Out: out, Module: "Api::Pets"})
+	generateSpec(t, spec("User"), Options{Out: out, Module: "Api::Users"})
+	generateSpec(t, spec("Nested"), Options{Out: out, Module: "Api::Pets::Nested"})
+	write("api/pets/stale.rb", "# Generated from OpenAPI specification for\n")
+	write("api/pets/hand_written.rb", "class HandWritten; end\n")
+	write("api/pets/notes.txt", "Generated from OpenAPI specification for\n")
+
+	files := generateSpec(t, spec("Dog"), Options{Out: out, Module: "Api::Pets", Clean: true})
+
+	for _, file := range []string{"api/pets/dog.rb", "api/pets/hand_written.rb", "api/pets/notes.txt", "api/users/user.rb", "api/pets/nested/nested.rb"} {
+		if _, ok := files[file]; !ok {
+			t.Errorf("%s was removed", file)
+		}
+	}
+	for _, file := range []string{"api/pets/pet.rb", "api/pets/stale.rb"} {
+		if _, ok := files[file]; ok {
+			t.Errorf("%s wasn't removed", file)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+	header := `=begin
+Generated from OpenAPI specification for
+  Test 1
+using
+  openapi-sorbet version devel`
+
+	t.Run("no timestamp", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{Index: "all.rb"})
+
+		for file, contents := range files {
+			if !strings.Contains(contents, header+".\nDO NOT EDIT.\n=end") {
+				t.Errorf("%s doesn't contain the header:\n%s", file, contents)
+			}
+		}
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		out := t.TempDir()
+		if _, err := Generate(Options{Input: []byte(spec), Out: out, SingleFile: "types.rb"}); err != nil {
+			t.Fatal(err)
+		}
+
+		files := readFiles(t, out)
+		_, after, ok := strings.Cut(files["types.rb"], header+" at ")
+		if !ok {
+			t.Fatalf("types.rb doesn't contain the header:\n%s", files["types.rb"])
+		}
+		generatedAt, _, _ := strings.Cut(after, ".\n")
+		if _, err := time.Parse(time.RFC3339, generatedAt); err != nil {
+			t.Errorf("the header's timestamp %q isn't RFC 3339: %v", generatedAt, err)
+		}
+	})
+
+	t.Run("info", func(t *testing.T) {
+		spec := strings.Replace(spec, `info: {title: Test, version: "1"}`, `info:
+  title: Test
+  version: "1"
+  description: The pets API
+  contact: {name: Pets Team, email: pets@example.com}
+  license: {name: MIT, url: https://opensource.org/licenses/MIT}`, 1)
+
+		files := generateSpec(t, spec, Options{})
+		assertContains(t, files, "pet.rb", `  Test 1
+
+  The pets API
+
+  Contact: Pets Team <pets@example.com>
+  License: MIT https://opensource.org/licenses/MIT
+using
+`)
+
+		files = generateSpec(t, spec, Options{Format: FormatRBS})
+		assertContains(t, files, "pet.rbs", "#\n#   The pets API\n#\n#   Contact: Pets Team <pets@example.com>\n")
+	})
+}
+
+func TestVersion(t *testing.T) {
+	version := Version()
+	if version == "" {
+		t.Fatal("Version() returned an empty version")
+	}
+
+	files := generateSpec(t, specWithSchemas("\n    Pet: {type: string}\n"), Options{})
+	assertContains(t, files, "pet.rb", "openapi-sorbet version "+version+".\n")
+}
+
+func TestTypedSigil(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{name: "default", want: "# typed: true\n"},
+		{name: "strict", opts: Options{TypedSigil: "strict"}, want: "# typed: strict\n"},
+		{name: "single file", opts: Options{TypedSigil: "strong", SingleFile: "types.rb"}, want: "# typed: strong\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, spec, tt.opts)
+
+			for file, got := range files {
+				if want := "# frozen_string_literal: true\n" + tt.want; !strings.HasPrefix(got, want) {
+					t.Errorf("%s doesn't begin with %q:\n%s", file, want, got)
+				}
+				if n := strings.Count(got, "# typed:"); n != 1 {
+					t.Errorf("%s has %d sigils, want 1:\n%s", file, n, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFrozenStringLiteral(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+
+	for _, opts := range []Options{{NoFrozenStringLiteral: true}, {NoFrozenStringLiteral: true, SingleFile: "types.rb"}} {
+		files := generateSpec(t, spec, opts)
+		for file, got := range files {
+			if !strings.HasPrefix(got, "# typed: true\n") || strings.Contains(got, "frozen_string_literal") {
+				t.Errorf("%s doesn't begin with the typed sigil, without the frozen_string_literal magic comment:\n%s", file, got)
+			}
+		}
+	}
+}
+
+// manySchemas returns an OpenAPI document with n object schemas, each with a property referring to the next
+func manySchemas(n int) string {
+	var spec strings.Builder
+	spec.WriteString(`
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  schemas:
+`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&spec, "    Schema%d:\n      type: object\n      properties:\n        name: {type: string}\n        count: {type: integer}\n", i)
+		fmt.Fprintf(&spec, "        next: {$ref: '#/components/schemas/Schema%d'}\n", (i+1)%n)
+	}
+	return spec.String()
+}
+
+func TestJobs(t *testing.T) {
+	const schemas = 100
+	spec := manySchemas(schemas)
+
+	want := generateSpec(t, spec, Options{Jobs: 1})
+	if len(want) != schemas+2 {
+		t.Fatalf("Generate() wrote %d files, want %d", len(want), schemas+2)
+	}
+	for i := 0; i < schemas; i++ {
+		if _, ok := want[fmt.Sprintf("schema_%d.rb", i)]; !ok {
+			t.Errorf("Generate() didn't write schema_%d.rb", i)
+		}
+	}
+
+	for _, jobs := range []int{2, 8, 64} {
+		t.Run(fmt.Sprintf("%d jobs", jobs), func(t *testing.T) {
+			got := generateSpec(t, spec, Options{Jobs: jobs})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Generate() with %d jobs differed from 1 job", jobs)
+			}
+		})
+	}
+}
+
+func BenchmarkJobs(b *testing.B) {
+	spec := []byte(manySchemas(600))
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("%d jobs", jobs), func(b *testing.B) {
+			opts := Options{
+				Input:       spec,
+				Out:         b.TempDir(),
+				Jobs:        jobs,
+				NoTimestamp: true,
+				LogLevel:    LogLevelError,
+			}
+
+			for i := 0; i < b.N; i++ {
+				if _, err := Generate(opts); err != nil {
+					b.Fatalf("Generate() returned an error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatRBI(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        status: {$ref: '#/components/schemas/Status'}
+    Status:
+      type: string
+      enum: [available, sold]
+`)
+
+	t.Run("file per type", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{Format: FormatRBI, Module: "Api"})
+		assertContains(t, files, "api/pet.rbi",
+			"# typed: true\n",
+			"module Api\n=begin",
+			"  class Pet < T::Struct\n    include HashDeserializable\n",
+			"    const :name, String\n",
+			"    const :status, T.nilable(Status)\n",
+		)
+		assertContains(t, files, "api/status.rbi", "    enums do\n      Available = new('available')\n")
+		assertContains(t, files, "api/hash_deserializable.rbi", "    def from_hash(hash); end")
+
+		for name := range files {
+			if filepath.Ext(name) != ".rbi" {
+				t.Errorf("Generate() wrote %s, but only RBI files should be generated", name)
+			}
+		}
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{Format: FormatRBI, SingleFile: "types.rbi"})
+		assertContains(t, files, "types.rbi",
+			"module HashDeserializable\n",
+			"class Pet < T::Struct\n",
+			"class Status < T::Enum\n",
+		)
+	})
+}
+
+func TestExtraPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeSpec := func(name string, schemas string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(specWithSchemas(schemas)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	pets := writeSpec("pets.yaml", `
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+    Error:
+      type: object
+      properties:
+        message: {type: string}
+`)
+	users := writeSpec("users.yaml", `
+    User:
+      type: object
+      properties:
+        error: {$ref: '#/components/schemas/Error'}
+    Error:
+      type: object
+      properties:
+        message: {type: string}
+`)
+	conflict := writeSpec("conflict.yaml", `
+    Pet:
+      type: object
+      properties:
+        id: {type: integer}
+`)
+
+	t.Run("merged", func(t *testing.T) {
+		files := generateSpec(t, "", Options{Path: pets, ExtraPaths: []string{users}})
+		assertContains(t, files, "pet.rb", "class Pet < T::Struct")
+		assertContains(t, files, "user.rb", "class User < T::Struct", "const :error, T.nilable(Error)")
+		assertContains(t, files, "error.rb", "class Error < T::Struct", "const :message, T.nilable(String)")
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		files := generateSpec(t, "", Options{Path: filepath.Join(dir, "[pu]*.yaml")})
+		assertContains(t, files, "pet.rb", "class Pet < T::Struct")
+		assertContains(t, files, "user.rb", "class User < T::Struct")
+
+		_, err := Generate(Options{Path: pets, ExtraPaths: []string{filepath.Join(dir, "*.json")}, Out: t.TempDir()})
+		if want := "no files match " + filepath.Join(dir, "*.json"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		out := t.TempDir()
+		_, err := Generate(Options{Path: pets, ExtraPaths: []string{users, conflict}, Out: out})
+		if want := "schema Pet in " + conflict + " conflicts with an existing definition of the same name"; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+		}
+		if files := readFiles(t, out); len(files) > 0 {
+			t.Errorf("Generate() wrote files despite the error: %v", sortedKeys(files))
+		}
+	})
+}
+
+// capture returns what's written to the file, such as os.Stdout, while f runs
+func capture(t testing.TB, file **os.File, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := *file
+	*file = w
+	defer func() { *file = original }()
+
+	read := make(chan string)
+	go func() {
+		contents, _ := io.ReadAll(r)
+		read <- string(contents)
+	}()
+
+	f()
+	w.Close()
+	return <-read
+}
+
+func TestStdout(t *testing.T) {
+	opts := Options{
+		Input:       []byte(specWithSchemas("\n    Owner: {type: object}\n    Pet: {type: object, properties: {name: {type: string}}}\n")),
+		Out:         OutStdout,
+		Exclude:     []string{"Owner"},
+		SingleFile:  "types.rb",
+		NoTimestamp: true,
+		LogLevel:    LogLevelDebug,
+	}
+
+	var stdout string
+	logs := capture(t, &os.Stderr, func() {
+		stdout = capture(t, &os.Stdout, func() {
+			if _, err := Generate(opts); err != nil {
+				t.Errorf("Generate() returned an error: %v", err)
+			}
+		})
+	})
+
+	if !strings.Contains(stdout, "const :name, T.nilable(String)") {
+		t.Errorf("stdout doesn't contain the Pet class:\n%s", stdout)
+	}
+	if !strings.HasPrefix(stdout, "# frozen_string_literal: true\n") {
+		t.Errorf("stdout doesn't only contain the generated code:\n%s", stdout)
+	}
+	if !strings.Contains(logs, "Skipping Owner as filtered") || strings.Contains(stdout, "Skipping") {
+		t.Errorf("the logs weren't only written to stderr:\n%s", logs)
+	}
+	if _, err := os.Stat(OutStdout); err == nil {
+		t.Errorf("Generate() created a %s directory", OutStdout)
+	}
+
+	opts.SingleFile = ""
+	_, err := Generate(opts)
+	if want := "writing to stdout requires SingleFile"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+
+	t.Run("custom", func(t *testing.T) {
+		path := writeFile(t, "custom.tmpl", "# custom {{ .Type.TypeName }}\n{{ range .Type.Properties }}# {{ .Name }}: {{ .SorbetType }}\n{{ end }}")
+		files := generateSpec(t, spec, Options{Template: path})
+		if got, want := files["pet.rb"], "# custom Pet\n# name: T.nilable(String)\n"; got != want {
+			t.Errorf("pet.rb = %q, want %q", got, want)
+		}
+	})
+
+	for _, tt := range []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{name: "invalid", template: "{{ .Type.TypeName ", want: []string{"failed to parse the template", ".Type.TypeName"}},
+		{name: "unknown field", template: "{{ .Type.Missing }}", want: []string{"failed to render the template", ".Metadata.Modules"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "custom.tmpl", tt.template)
+			_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), Template: path})
+			if err == nil {
+				t.Fatal("Generate() didn't return an error")
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("Generate() returned the error %q, want it to contain %q", err, w)
+				}
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict %v", strict), func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "types")
+
+			var err error
+			logs := capture(t, &os.Stderr, func() {
+				_, err = Generate(Options{Input: []byte(spec), Out: out, DryRun: true, Strict: strict, NoTimestamp: true})
+			})
+			if err != nil {
+				t.Fatalf("Generate() returned an error: %v", err)
+			}
+
+			if _, err := os.Stat(out); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Generate() created the output directory when dry running: %v", err)
+			}
+			for _, file := range []string{"pet.rb", "types.rb", "hash_deserializable.rb"} {
+				if want := "Would write " + filepath.Join(out, file) + " ("; !strings.Contains(logs, want) {
+					t.Errorf("the plan doesn't contain %q:\n%s", want, logs)
+				}
+			}
+		})
+	}
+
+	t.Run("clean", func(t *testing.T) {
+		out := t.TempDir()
+		generateSpec(t, spec, Options{Out: out})
+		before := readFiles(t, out)
+
+		_, logs := generateSpecLogs(t, specWithSchemas("\n    Owner: {type: string}\n"), Options{Out: out, DryRun: true, Clean: true})
+		if want := "Would remove previously generated " + filepath.Join(out, "pet.rb"); !strings.Contains(logs, want) {
+			t.Errorf("the plan doesn't contain %q:\n%s", want, logs)
+		}
+		if after := readFiles(t, out); !reflect.DeepEqual(before, after) {
+			t.Errorf("Generate() modified the output directory when dry running")
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+	spec := func(schemas ...string) string {
+		var sb strings.Builder
+		for _, schema := range schemas {
+			sb.WriteString("\n    " + schema + ": {type: object, properties: {name: {type: string}}}")
+		}
+		return specWithSchemas(sb.String() + "\n")
+	}
+	check := func(spec string, opts Options) error {
+		opts.Input = []byte(spec)
+		opts.Check = true
+		opts.NoTimestamp = true
+		_, err := Generate(opts)
+		return err
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		out := t.TempDir()
+		generateSpec(t, spec("Pet"), Options{Out: out})
+		before := readFiles(t, out)
+
+		if err := check(spec("Pet"), Options{Out: out}); err != nil {
+			t.Errorf("Generate() returned an error for up to date files: %v", err)
+		}
+		if after := readFiles(t, out); !reflect.DeepEqual(before, after) {
+			t.Errorf("Generate() modified the output directory when checking")
+		}
+	})
+
+	t.Run("differences", func(t *testing.T) {
+		out := t.TempDir()
+		generateSpec(t, spec("Pet", "Owner"), Options{Out: out})
+		if err := os.WriteFile(filepath.Join(out, "pet.rb"), []byte("# Generated from OpenAPI specification for\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := check(spec("Pet", "User"), Options{Out: out})
+		if err == nil {
+			t.Fatal("Generate() didn't return an error for stale files")
+		}
+		for _, want := range []string{
+			"4 generated files differ from those in " + out,
+			filepath.Join(out, "pet.rb") + " is stale, first differing at line 1",
+			filepath.Join(out, "types.rb") + " is stale",
+			filepath.Join(out, "user.rb") + " is missing",
+			filepath.Join(out, "owner.rb") + " would no longer be generated",
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+			}
+		}
+	})
+
+	t.Run("modules", func(t *testing.T) {
+		// sibling and nested modules generated to the same output directory aren't reported as no longer generated
+		out := t.TempDir()
+		generateSpec(t, spec("Pet"), Options{Out: out, Module: "Api::Pets"})
+		generateSpec(t, spec("User"), Options{Out: out, Module: "Api::Users"})
+		generateSpec(t, spec("Nested"), Options{Out: out, Module: "Api::Pets::Nested"})
+
+		if err := check(spec("Pet"), Options{Out: out, Module: "Api::Pets"}); err != nil {
+			t.Errorf("Generate() returned an error for up to date files: %v", err)
+		}
+		if err := check(spec("User"), Options{Out: out, Module: "Api::Users"}); err != nil {
+			t.Errorf("Generate() returned an error for up to date files: %v", err)
+		}
+	})
+
+	t.Run("with a timestamp", func(t *testing.T) {
+		_, err := Generate(Options{Input: []byte(spec("Pet")), Out: t.TempDir(), Check: true})
+		if want := "Check requires NoTimestamp"; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+		}
+	})
+}
+
+// BenchmarkGenerate generates a document where each schema has many `$ref`s to the same schemas, so is dominated by
+// resolving and parsing the `$ref`s
+func BenchmarkGenerate(b *testing.B) {
+	var spec strings.Builder
+	spec.WriteString(`
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  parameters:
+    Filter:
+      name: filter
+      in: query
+      schema:
+        type: object
+        properties:
+          query: {type: string}
+          limit: {type: integer}
+  schemas:
+    Status:
+      type: string
+      enum: [active, inactive]
+`)
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&spec, "    Schema%d:\n      type: object\n      properties:\n", i)
+		for j := 0; j < 20; j++ {
+			fmt.Fprintf(&spec, "        status%d: {$ref: '#/components/schemas/Status'}\n", j)
+			fmt.Fprintf(&spec, "        filter%d: {$ref: '#/components/parameters/Filter/schema'}\n", j)
+		}
+	}
+
+	opts := Options{
+		Input:       []byte(spec.String()),
+		Out:         b.TempDir(),
+		NoTimestamp: true,
+		LogLevel:    LogLevelError,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Generate(opts); err != nil {
+			b.Fatalf("Generate() returned an error: %v", err)
+		}
+	}
+}
+
+func TestOutFileExt(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        owner: {$ref: '#/components/schemas/Owner'}
+    Owner:
+      type: object
+      properties:
+        id: {type: integer}
+`)
+
+	t.Run("rb", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{OutFileExt: ".gen.rb"})
+		assertContains(t, files, "pet.gen.rb", "require_relative './owner.gen'", "class Pet < T::Struct")
+		assertContains(t, files, "owner.gen.rb", "class Owner < T::Struct")
+		assertContains(t, files, "types.rb", "require_relative 'pet.gen'")
+		if _, ok := files["pet.rb"]; ok {
+			t.Errorf("pet.rb was generated, rather than pet.gen.rb")
+		}
+	})
+
+	t.Run("rbi", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{Format: FormatRBI, OutFileExt: ".gen.rbi"})
+		assertContains(t, files, "pet.gen.rbi", "class Pet < T::Struct")
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		out := t.TempDir()
+		generateSpec(t, spec, Options{Out: out, OutFileExt: ".gen.rb"})
+		stale := filepath.Join(out, "stale.txt")
+		if err := os.WriteFile(stale, []byte("# "+generatedMarker), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		files := generateSpec(t, specWithSchemas("\n    Owner: {type: object, properties: {id: {type: integer}}}\n"), Options{Out: out, OutFileExt: ".gen.rb", Clean: true})
+		if _, ok := files["pet.gen.rb"]; ok {
+			t.Errorf("pet.gen.rb wasn't cleaned")
+		}
+		if _, ok := files["stale.txt"]; !ok {
+			t.Errorf("stale.txt was cleaned, but doesn't have the OutFileExt")
+		}
+	})
+
+	for _, tt := range []struct {
+		name string
+		ext  string
+		want string
+	}{
+		{name: "without a dot", ext: "rb", want: `invalid OutFileExt "rb"`},
+		{name: "only a dot", ext: ".", want: `invalid OutFileExt "."`},
+		{name: "not .rb", ext: ".ruby", want: `the OutFileExt ".ruby" must end in .rb with the rb Format`},
+		{name: "rbi with the rb Format", ext: ".rbi", want: "To generate .rbi files, use the rbi Format, i.e. `-format rbi`"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), OutFileExt: tt.ext})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() returned the error %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnchangedFiles(t *testing.T) {
+	out := t.TempDir()
+	generateSpec(t, specWithSchemas(`
+    Owner: {type: object, properties: {name: {type: string}}}
+    Pet: {type: object, properties: {name: {type: string}}}
+`), Options{Out: out})
+
+	// backdate the files, so a rewrite is detectable regardless of the filesystem's timestamp resolution
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, file := range []string{"owner.rb", "pet.rb"} {
+		if err := os.Chtimes(filepath.Join(out, file), past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, logs := generateSpecLogs(t, specWithSchemas(`
+    Owner: {type: object, properties: {name: {type: string}, age: {type: integer}}}
+    Pet: {type: object, properties: {name: {type: string}}}
+`), Options{Out: out, Clean: true})
+
+	for _, tt := range []struct {
+		file      string
+		unchanged bool
+	}{
+		{file: "owner.rb", unchanged: false},
+		{file: "pet.rb", unchanged: true},
+	} {
+		info, err := os.Stat(filepath.Join(out, tt.file))
+		if err != nil {
+			t.Fatalf("%s was removed: %v", tt.file, err)
+		}
+		if got := info.ModTime().Equal(past); got != tt.unchanged {
+			t.Errorf("%s has the modification time %v, preserved = %v, want %v", tt.file, info.ModTime(), got, tt.unchanged)
+		}
+	}
+	assertContains(t, files, "owner.rb", "const :age, T.nilable(Integer)")
+	if want := "Skipped writing 3 unchanged files"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+}
@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// interfaceBases returns the names of the schemas which are only used as an `allOf` base of at least two other
+// schemas, so have no concrete instances, and can be generated as an interface which each of their children include.
+// A schema that is referenced in any other way, such as by a property, must be a concrete struct
+func interfaceBases(schemas map[string]*base.SchemaProxy) map[string]bool {
+	allOfRefs := make(map[string]int)
+	used := make(map[string]bool)
+
+	var walk func(sp *base.SchemaProxy)
+	walk = func(sp *base.SchemaProxy) {
+		if sp == nil {
+			return
+		}
+		if ref, ok := refProperty(sp); ok {
+			used[refSchemaName(ref.GetReference())] = true
+			return
+		}
+
+		v := sp.Schema()
+		if v == nil {
+			return
+		}
+
+		for _, member := range v.AllOf {
+			if isSchemaRef(member) {
+				allOfRefs[refSchemaName(member.GetReference())]++
+			} else {
+				walk(member)
+			}
+		}
+
+		for _, members := range [][]*base.SchemaProxy{v.OneOf, v.AnyOf, v.PrefixItems} {
+			for _, member := range members {
+				walk(member)
+			}
+		}
+		for _, property := range v.Properties {
+			walk(property)
+		}
+		if v.Items != nil && v.Items.IsA() {
+			walk(v.Items.A)
+		}
+		if additional, ok := v.AdditionalProperties.(*base.SchemaProxy); ok {
+			walk(additional)
+		}
+		walk(v.Not)
+	}
+
+	for _, sp := range schemas {
+		walk(sp)
+	}
+
+	bases := make(map[string]bool)
+	for name, count := range allOfRefs {
+		sp, ok := schemas[name]
+		if !ok || count < 2 || used[name] || isSchemaRef(sp) || !isStructSchema(sp.Schema()) {
+			continue
+		}
+		bases[name] = true
+	}
+	return bases
+}
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// LogLevelDebug logs the decisions made while generating, such as schemas being filtered or types being inferred
+	LogLevelDebug = "debug"
+	// LogLevelInfo logs what is being generated
+	LogLevelInfo = "info"
+	// LogLevelWarn logs problems with the schemas, which may mean they aren't generated exactly as described
+	LogLevelWarn = "warn"
+	// LogLevelError only logs errors which prevent generation
+	LogLevelError = "error"
+)
+
+// LogLevels are the valid minimum levels to log at, from the most to least verbose
+var LogLevels = []string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+
+const (
+	// LogFormatText logs each message as a line of text, prefixed by the time, and by its level if it's not info
+	LogFormatText = "text"
+	// LogFormatJSON logs each message as a line of JSON, with `time`, `level` and `msg` fields, and the `schema`,
+	// `property` and `kind` of diagnostics
+	LogFormatJSON = "json"
+)
+
+// LogFormats are the valid formats to log in
+var LogFormats = []string{LogFormatText, LogFormatJSON}
+
+// logger writes leveled log messages, in either LogFormatText or LogFormatJSON
+type logger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	level  int
+	format string
+	// text formats the LogFormatText messages, in the same way as the standard logger
+	text *log.Logger
+}
+
+func newLogger(out io.Writer, level string, format string) *logger {
+	return &logger{
+		out:    out,
+		level:  slices.Index(LogLevels, level),
+		format: format,
+		text:   log.New(out, "", log.LstdFlags),
+	}
+}
+
+// logEntry is a LogFormatJSON message
+type logEntry struct {
+	Time     string         `json:"time"`
+	Level    string         `json:"level"`
+	Message  string         `json:"msg"`
+	Schema   string         `json:"schema,omitempty"`
+	Property string         `json:"property,omitempty"`
+	Kind     DiagnosticKind `json:"kind,omitempty"`
+}
+
+func (l *logger) log(entry logEntry) {
+	if slices.Index(LogLevels, entry.Level) < l.level {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.format == LogFormatJSON {
+		entry.Time = time.Now().Format(time.RFC3339)
+		b, err := json.Marshal(entry)
+		if err != nil {
+			// each field is a string, so this shouldn't happen
+			panic(err)
+		}
+		l.out.Write(append(b, '\n'))
+		return
+	}
+
+	if entry.Level == LogLevelInfo {
+		l.text.Print(entry.Message)
+	} else {
+		l.text.Printf("%s: %s", levelPrefixes[entry.Level], entry.Message)
+	}
+}
+
+// levelPrefixes are the prefixes of LogFormatText messages at each level, other than info
+var levelPrefixes = map[string]string{
+	LogLevelDebug: "DEBUG",
+	LogLevelWarn:  "WARN",
+	LogLevelError: "ERROR",
+}
+
+func (l *logger) debugf(format string, args ...any) {
+	l.log(logEntry{Level: LogLevelDebug, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *logger) infof(format string, args ...any) {
+	l.log(logEntry{Level: LogLevelInfo, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *logger) warnf(format string, args ...any) {
+	l.log(logEntry{Level: LogLevelWarn, Message: fmt.Sprintf(format, args...)})
+}
+
+// diagnostic logs a Diagnostic as a warning, with its schema, property and kind as separate fields in LogFormatJSON
+func (l *logger) diagnostic(d Diagnostic) {
+	l.log(logEntry{
+		Level:    LogLevelWarn,
+		Message:  d.String(),
+		Schema:   d.Schema,
+		Property: d.Property,
+		Kind:     d.Kind,
+	})
+}
+
+// Fatal logs the error to stderr at the error level, in the given format, one of LogFormats, and exits
+func Fatal(format string, err error) {
+	newLogger(os.Stderr, LogLevelError, format).log(logEntry{Level: LogLevelError, Message: err.Error()})
+	os.Exit(1)
+}
@@ -0,0 +1,122 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	spec := specWithSchemas(`
+    Owner: {type: object}
+    Pet:
+      properties:
+        name: {type: foo}
+`)
+
+	for _, tt := range []struct {
+		level   string
+		want    []string
+		notWant []string
+	}{
+		{
+			level: LogLevelDebug,
+			want:  []string{"DEBUG: Skipping Owner as filtered", "DEBUG: Pet has no Type", "WARN: Pet.name ", "Generated types.rb"},
+		},
+		{
+			level:   LogLevelInfo,
+			want:    []string{"WARN: Pet.name ", "Generated types.rb"},
+			notWant: []string{"DEBUG"},
+		},
+		{
+			level:   LogLevelWarn,
+			want:    []string{"WARN: Pet.name "},
+			notWant: []string{"DEBUG", "Generated"},
+		},
+		{
+			level:   LogLevelError,
+			notWant: []string{"DEBUG", "WARN", "Generated"},
+		},
+	} {
+		t.Run(tt.level, func(t *testing.T) {
+			_, logs := generateSpecLogs(t, spec, Options{Exclude: []string{"Owner"}, LogLevel: tt.level})
+			for _, w := range tt.want {
+				if !strings.Contains(logs, w) {
+					t.Errorf("didn't log %q:\n%s", w, logs)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(logs, w) {
+					t.Errorf("logged %q:\n%s", w, logs)
+				}
+			}
+		})
+	}
+
+	_, err := Generate(Options{Input: []byte(spec), Out: t.TempDir(), LogLevel: "verbose"})
+	if want := `invalid LogLevel "verbose"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Generate() returned the error %v, want it to contain %q", err, want)
+	}
+}
+
+func TestLogFormatJSON(t *testing.T) {
+	spec := specWithSchemas(`
+    io.k8s.api.core.v1.Pod:
+      type: object
+      properties:
+        name: {type: string}
+        spec: {type: foo}
+`)
+
+	_, logs := generateSpecLogs(t, spec, Options{LogFormat: LogFormatJSON})
+
+	var entries []logEntry
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		var entry logEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to parse the log line %q as JSON: %v", line, err)
+		}
+		if entry.Time == "" || entry.Level == "" || entry.Message == "" {
+			t.Errorf("the log line %q is missing its time, level or msg", line)
+		}
+		entries = append(entries, entry)
+	}
+
+	var diagnostics []logEntry
+	for _, entry := range entries {
+		if entry.Kind != "" {
+			diagnostics = append(diagnostics, entry)
+		}
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("logged %d diagnostics, want 1:\n%s", len(diagnostics), logs)
+	}
+
+	got := diagnostics[0]
+	if got.Level != LogLevelWarn || got.Schema != "io.k8s.api.core.v1.Pod" || got.Property != "spec" || got.Kind != DiagnosticUntyped {
+		t.Errorf("logged the diagnostic %+v, want it at the warn level, for the spec property of io.k8s.api.core.v1.Pod", got)
+	}
+	if want := "io.k8s.api.core.v1.Pod.spec "; !strings.HasPrefix(got.Message, want) {
+		t.Errorf("logged the diagnostic's msg %q, want it to start with %q", got.Message, want)
+	}
+}
+
+func TestGenerateConcurrently(t *testing.T) {
+	spec := specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n")
+	formats := []string{LogFormatText, LogFormatJSON}
+
+	errs := make(chan error, len(formats))
+	for _, format := range formats {
+		opts := Options{Input: []byte(spec), Out: t.TempDir(), NoTimestamp: true, LogLevel: LogLevelError, LogFormat: format}
+		go func() {
+			_, err := Generate(opts)
+			errs <- err
+		}()
+	}
+
+	for range formats {
+		if err := <-errs; err != nil {
+			t.Errorf("Generate() returned an error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// manifestEntry describes a generated type in the Manifest, so tooling can map schemas to the files they're generated
+// in
+type manifestEntry struct {
+	SchemaName string `json:"schema_name"`
+	// TypeName is the fully qualified name of the type, within any Module
+	TypeName string `json:"type_name"`
+	// File is the path to the file that the type is generated in, relative to the output directory, which is omitted
+	// when writing to stdout
+	File       string             `json:"file,omitempty"`
+	Kind       string             `json:"kind"`
+	Properties []manifestProperty `json:"properties,omitempty"`
+}
+
+// manifestProperty describes a property of a generated struct or interface in the Manifest
+type manifestProperty struct {
+	Name       string `json:"name"`
+	SchemaName string `json:"schema_name"`
+	Type       string `json:"type"`
+	Required   bool   `json:"required"`
+}
+
+// Kind returns how the Type is generated, one of `struct`, `interface`, `module` (for a discriminated `oneOf`),
+// `enum`, `wrapper`, `ref` (for a constant referring to another type) or `alias`
+func (t Type) Kind() string {
+	switch {
+	case t.IsRefAlias:
+		return "ref"
+	case t.IsWrapper:
+		return "wrapper"
+	case t.IsMap:
+		return "alias"
+	case t.IsEnum():
+		return "enum"
+	case t.IsObject():
+		return "struct"
+	case t.IsInterface:
+		return "interface"
+	case t.Discriminator != nil:
+		return "module"
+	default:
+		return "alias"
+	}
+}
+
+// writeManifest writes a JSON array describing each of the types to the Manifest, if one is configured
+func writeManifest(w *fileWriter, opts Options, modules []string, dirs []string, types []Type) error {
+	if opts.Manifest == "" {
+		return nil
+	}
+
+	entries := make([]manifestEntry, 0, len(types))
+	for _, t := range types {
+		entry := manifestEntry{
+			SchemaName: t.SchemaName,
+			TypeName:   strings.Join(append(slices.Clone(modules), t.TypeName), "::"),
+			Kind:       t.Kind(),
+		}
+
+		switch {
+		case opts.Out == OutStdout:
+		case opts.SingleFile != "":
+			entry.File = path.Join(append(slices.Clone(dirs), opts.SingleFile)...)
+		default:
+			entry.File = path.Join(append(slices.Clone(dirs), t.Filename+opts.OutFileExt)...)
+		}
+
+		for _, p := range t.Properties {
+			entry.Properties = append(entry.Properties, manifestProperty{
+				Name:       p.Name,
+				SchemaName: p.SchemaName,
+				Type:       p.SorbetType(),
+				Required:   p.Required,
+			})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// ensure that we have consistent output
+	slices.SortStableFunc(entries, func(a, b manifestEntry) bool {
+		return a.SchemaName < b.SchemaName
+	})
+
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = w.writeFile(opts.Manifest, append(b, '\n'))
+	if err != nil {
+		return err
+	}
+
+	if opts.Out != OutStdout {
+		w.generated("Generated %s describing all types", opts.Manifest)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        pet_status: {$ref: '#/components/schemas/Status'}
+    Status:
+      type: string
+      enum: [available, sold]
+`)
+
+	manifest := filepath.Join(t.TempDir(), "manifest.json")
+	generateSpec(t, spec, Options{Manifest: manifest, Module: "Api"})
+
+	contents, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatalf("failed to read the manifest: %v", err)
+	}
+	var got []manifestEntry
+	if err := json.Unmarshal(contents, &got); err != nil {
+		t.Fatalf("failed to parse the manifest: %v\n%s", err, contents)
+	}
+
+	want := []manifestEntry{
+		{
+			SchemaName: "Pet",
+			TypeName:   "Api::Pet",
+			File:       "api/pet.rb",
+			Kind:       "struct",
+			Properties: []manifestProperty{
+				{Name: "name", SchemaName: "name", Type: "String", Required: true},
+				{Name: "pet_status", SchemaName: "pet_status", Type: "T.nilable(Status)"},
+			},
+		},
+		{SchemaName: "Status", TypeName: "Api::Status", File: "api/status.rb", Kind: "enum"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the manifest contained %+v, want %+v", got, want)
+	}
+}
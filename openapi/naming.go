@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+	"golang.org/x/exp/slices"
+)
+
+// invalidIdentifierChars matches the characters that can't be used in a Ruby identifier
+var invalidIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// rubyKeywords contains Ruby's reserved words, which can't be used as identifiers
+var rubyKeywords = map[string]bool{
+	"__ENCODING__": true, "__LINE__": true, "__FILE__": true, "BEGIN": true, "END": true, "alias": true, "and": true,
+	"begin": true, "break": true, "case": true, "class": true, "def": true, "defined?": true, "do": true, "else": true,
+	"elsif": true, "end": true, "ensure": true, "false": true, "for": true, "if": true, "in": true, "module": true,
+	"next": true, "nil": true, "not": true, "or": true, "redo": true, "rescue": true, "retry": true, "return": true,
+	"self": true, "super": true, "then": true, "true": true, "undef": true, "unless": true, "until": true,
+	"when": true, "while": true, "yield": true,
+}
+
+// typeName returns the Ruby constant name for a schema, in the given case, one of NameCases. For NameCasePascal,
+// `pet_owner` will be `PetOwner`, for NameCaseSnake `Pet_owner`, and NameCasePreserve keeps the original name, only
+// capitalising its first letter. Names that would be invalid constants, such as those beginning with a digit, are
+// prefixed with `Schema`
+func typeName(name string, nameCase string) string {
+	var n string
+	switch nameCase {
+	case NameCaseSnake:
+		n = capitalize(strcase.ToSnake(name))
+	case NameCasePreserve:
+		n = capitalize(name)
+	default:
+		n = strcase.ToCamel(name)
+	}
+	n = invalidIdentifierChars.ReplaceAllString(n, "")
+	if n == "" || !unicode.IsUpper([]rune(n)[0]) || rubyKeywords[n] {
+		n = "Schema" + n
+	}
+	return n
+}
+
+// fileName returns the name of the file, without extension, that a schema's type is generated in, in the given case,
+// one of NameCases. For NameCaseSnake, this is derived from the type's name, so `PetOwner` will be `pet_owner`, whereas
+// NameCasePreserve keeps the original schema name
+func fileName(name string, typeName string, nameCase string) string {
+	switch nameCase {
+	case NameCasePascal:
+		return strcase.ToCamel(typeName)
+	case NameCasePreserve:
+		if n := invalidIdentifierChars.ReplaceAllString(name, ""); n != "" {
+			return n
+		}
+		return typeName
+	default:
+		return strcase.ToSnake(typeName)
+	}
+}
+
+// capitalize upper cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// propName returns the Ruby name for a property, i.e. `petOwner` will be `pet_owner`. Names that would be invalid
+// identifiers, such as reserved words, or those beginning with a digit, are prefixed with `_`
+func propName(name string) string {
+	n := invalidIdentifierChars.ReplaceAllString(strcase.ToSnake(name), "")
+	if n == "" || unicode.IsDigit([]rune(n)[0]) || rubyKeywords[n] {
+		n = "_" + n
+	}
+	return n
+}
+
+// reservedTypeNames are the constants defined by, or for, the files this tool generates alongside the types
+var reservedTypeNames = []string{"HashDeserializable", "Types"}
+
+// reservedFileNames are the files, without extension, that this tool generates alongside the types
+var reservedFileNames = []string{"hash_deserializable", "types"}
+
+// validateNames checks that no two schemas are generated as the same type, or in the same file, as one would silently
+// overwrite the other, i.e. `user-profile` and `user_profile` are both `UserProfile`. Neither can a schema be generated
+// as one of the reservedTypeNames, or in one of the reservedFileNames. Files are compared case insensitively, as they'd
+// collide on case insensitive filesystems. Files aren't checked when generating a single file
+func validateNames(types []Type, singleFile bool) error {
+	var errs []error
+
+	typeNames := make(map[string]string)
+	fileNames := make(map[string]string)
+	for _, t := range types {
+		if slices.Contains(reservedTypeNames, t.TypeName) {
+			errs = append(errs, fmt.Errorf("schema %s is generated as the type %s, which is reserved by this tool", t.SchemaName, t.TypeName))
+			continue
+		}
+		if !singleFile && slices.Contains(reservedFileNames, strings.ToLower(t.Filename)) {
+			errs = append(errs, fmt.Errorf("schema %s is generated in the file %s, which is reserved by this tool", t.SchemaName, t.Filename))
+			continue
+		}
+
+		if other, ok := typeNames[t.TypeName]; ok {
+			errs = append(errs, fmt.Errorf("schemas %s and %s are both generated as the type %s", other, t.SchemaName, t.TypeName))
+		} else {
+			typeNames[t.TypeName] = t.SchemaName
+		}
+
+		if singleFile {
+			continue
+		}
+
+		key := strings.ToLower(t.Filename)
+		if other, ok := fileNames[key]; ok {
+			errs = append(errs, fmt.Errorf("schemas %s and %s are both generated in the file %s", other, t.SchemaName, t.Filename))
+		} else {
+			fileNames[key] = t.SchemaName
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("generated names collide, so schemas should be renamed or excluded: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
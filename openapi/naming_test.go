@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		name      string
+		classCase string
+		fileCase  string
+		want      string
+		wantFile  string
+	}{
+		{name: "pet_owner", want: "PetOwner", wantFile: "pet_owner"},
+		{name: "Pet.Owner", want: "PetOwner", wantFile: "pet_owner"},
+		{name: "2fa", want: "Schema2Fa", wantFile: "schema_2_fa"},
+		{name: "_", want: "Schema", wantFile: "schema"},
+		{name: "user_profile", classCase: NameCaseSnake, fileCase: NameCasePascal, want: "User_profile", wantFile: "UserProfile"},
+		{name: "user_profile", classCase: NameCasePreserve, fileCase: NameCasePreserve, want: "User_profile", wantFile: "user_profile"},
+		{name: "userProfile", classCase: NameCasePreserve, fileCase: NameCasePreserve, want: "UserProfile", wantFile: "userProfile"},
+		{name: "Pet.Owner", classCase: NameCasePreserve, fileCase: NameCasePreserve, want: "PetOwner", wantFile: "PetOwner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.classCase+" "+tt.fileCase, func(t *testing.T) {
+			classCase, fileCase := tt.classCase, tt.fileCase
+			if classCase == "" {
+				classCase = NameCasePascal
+			}
+			if fileCase == "" {
+				fileCase = NameCaseSnake
+			}
+
+			got := typeName(tt.name, classCase)
+			if got != tt.want {
+				t.Errorf("typeName(%q, %q) = %q, want %q", tt.name, classCase, got, tt.want)
+			}
+			if got := fileName(tt.name, got, fileCase); got != tt.wantFile {
+				t.Errorf("fileName(%q, %q) = %q, want %q", tt.name, fileCase, got, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestPropName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "petOwner", want: "pet_owner"},
+		{name: "class", want: "_class"},
+		{name: "end", want: "_end"},
+		{name: "2fa", want: "_2_fa"},
+		{name: "x-request-id", want: "x_request_id"},
+		{name: "$ref", want: "ref"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := propName(tt.name); got != tt.want {
+				t.Errorf("propName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReservedAndInvalidNames(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Login:
+      type: object
+      properties:
+        class: {type: string}
+        2fa: {type: boolean}
+    2fa: {type: string, enum: [sms, totp]}
+`), Options{})
+
+	assertContains(t, files, "login.rb",
+		"const :_class, T.nilable(String), name: 'class'",
+		"const :_2_fa, T.nilable(T::Boolean), name: '2fa'",
+	)
+	assertContains(t, files, "schema_2_fa.rb", "class Schema2Fa < T::Enum")
+}
+
+func TestNameCases(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    user_profile:
+      type: object
+      properties:
+        owner: {$ref: '#/components/schemas/pet_owner'}
+    pet_owner:
+      type: object
+      properties:
+        name: {type: string}
+`), Options{ClassCase: NameCasePreserve, FileCase: NameCasePreserve})
+
+	assertContains(t, files, "user_profile.rb",
+		"class User_profile < T::Struct",
+		"const :owner, T.nilable(Pet_owner)",
+	)
+	assertContains(t, files, "pet_owner.rb", "class Pet_owner < T::Struct")
+}
+
+func TestNameCollisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemas string
+		opts    Options
+		want    []string
+	}{
+		{
+			name:    "type and file",
+			schemas: "\n    user-profile: {type: object}\n    user_profile: {type: object}\n",
+			want: []string{
+				"schemas user-profile and user_profile are both generated as the type UserProfile",
+				"schemas user-profile and user_profile are both generated in the file user_profile",
+			},
+		},
+		{
+			name:    "file case insensitively",
+			schemas: "\n    UserProfile: {type: object}\n    userProfile: {type: object}\n",
+			opts:    Options{ClassCase: NameCasePreserve, FileCase: NameCasePreserve},
+			want:    []string{"schemas UserProfile and userProfile are both generated in the file userProfile"},
+		},
+		{
+			name:    "HashDeserializable",
+			schemas: "\n    HashDeserializable: {type: object}\n",
+			want:    []string{"schema HashDeserializable is generated as the type HashDeserializable, which is reserved by this tool"},
+		},
+		{
+			name:    "Types",
+			schemas: "\n    Types: {type: object}\n",
+			opts:    Options{SingleFile: "all.rb"},
+			want:    []string{"schema Types is generated as the type Types, which is reserved by this tool"},
+		},
+		{
+			name:    "types.rb",
+			schemas: "\n    TYPES: {type: object}\n",
+			opts:    Options{ClassCase: NameCasePreserve, FileCase: NameCasePreserve},
+			want:    []string{"schema TYPES is generated in the file TYPES, which is reserved by this tool"},
+		},
+		{
+			name:    "hash_deserializable.rb",
+			schemas: "\n    hash_deserializable: {type: object}\n",
+			opts:    Options{ClassCase: NameCasePreserve},
+			want:    []string{"schema hash_deserializable is generated in the file hash_deserializable, which is reserved by this tool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Input = []byte(specWithSchemas(tt.schemas))
+			opts.Out = t.TempDir()
+
+			_, err := Generate(opts)
+			if err == nil {
+				t.Fatal("Generate() didn't return an error")
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("Generate() returned the error %q, want it to contain %q", err, w)
+				}
+			}
+			if files := readFiles(t, opts.Out); len(files) > 0 {
+				t.Errorf("Generate() wrote files despite the error: %v", sortedKeys(files))
+			}
+		})
+	}
+}
+
+func TestPreferTitle(t *testing.T) {
+	spec := specWithSchemas(`
+    pet_v2:
+      title: Pet
+      type: object
+      properties:
+        owner: {$ref: '#/components/schemas/owner_v2'}
+    owner_v2:
+      type: object
+      properties:
+        name: {type: string}
+    Shelter:
+      type: object
+      properties:
+        pet: {$ref: '#/components/schemas/pet_v2'}
+`)
+
+	files := generateSpec(t, spec, Options{PreferTitle: true})
+	assertContains(t, files, "pet_v_2.rb", "class Pet < T::Struct", "const :owner, T.nilable(OwnerV2)")
+	assertContains(t, files, "owner_v_2.rb", "class OwnerV2 < T::Struct")
+	assertContains(t, files, "shelter.rb", "require_relative './pet_v_2'", "const :pet, T.nilable(Pet)")
+
+	files = generateSpec(t, spec, Options{})
+	assertContains(t, files, "pet_v_2.rb", "class PetV2 < T::Struct")
+}
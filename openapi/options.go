@@ -0,0 +1,438 @@
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// EnumStyleTEnum generates enums as a class inheriting from `T::Enum`
+	EnumStyleTEnum = "tenum"
+	// EnumStyleAlias generates enums as a type alias of the underlying type
+	EnumStyleAlias = "alias"
+)
+
+// OutStdout is the Out which writes the generated code to stdout, rather than to files, when generating a SingleFile
+const OutStdout = "-"
+
+const (
+	// AliasStyleType generates schemas that aren't structs or enums as a `T.type_alias`
+	AliasStyleType = "type"
+	// AliasStyleWrapper generates schemas that aren't structs or enums as a class wrapping a typed `value`
+	AliasStyleWrapper = "wrapper"
+)
+
+const (
+	// FormatRB generates executable Ruby files, defining the types at runtime
+	FormatRB = "rb"
+	// FormatRBI generates RBI files, declaring the types' signatures for Sorbet without any implementation
+	FormatRBI = "rbi"
+	// FormatRBS generates RBS files, declaring the types' signatures for RBS-based type checkers, such as Steep
+	FormatRBS = "rbs"
+)
+
+// Formats are the valid kinds of files to generate
+var Formats = []string{FormatRB, FormatRBI, FormatRBS}
+
+const (
+	// NameCasePascal generates names in PascalCase, i.e. `UserProfile`
+	NameCasePascal = "pascal"
+	// NameCaseSnake generates names in snake_case, i.e. `user_profile`, capitalised for class names
+	NameCaseSnake = "snake"
+	// NameCasePreserve generates names as they're named in the schema, capitalised for class names
+	NameCasePreserve = "preserve"
+)
+
+// NameCases are the valid cases for ClassCase and FileCase
+var NameCases = []string{NameCasePascal, NameCaseSnake, NameCasePreserve}
+
+const (
+	// RoleRequest generates types for request bodies, omitting `readOnly` properties
+	RoleRequest = "request"
+	// RoleResponse generates types for response bodies, omitting `writeOnly` properties
+	RoleResponse = "response"
+	// RoleBoth generates types for both requests and responses, with a comment on `readOnly` and `writeOnly` properties
+	RoleBoth = "both"
+)
+
+// Roles are the valid kinds of bodies to generate types for
+var Roles = []string{RoleRequest, RoleResponse, RoleBoth}
+
+const (
+	// ModuleStyleNested opens each of the Module's modules on its own line, i.e. `module Api` then `module V1`
+	ModuleStyleNested = "nested"
+	// ModuleStyleCompact opens the Module on a single line, i.e. `module Api::V1`, which requires its parent modules to
+	// already be defined
+	ModuleStyleCompact = "compact"
+)
+
+// ModuleStyles are the valid ways to open the Module
+var ModuleStyles = []string{ModuleStyleNested, ModuleStyleCompact}
+
+const (
+	// IndentSpaces indents generated code by two spaces per level
+	IndentSpaces = "2"
+	// IndentTab indents generated code by a tab per level
+	IndentTab = "tab"
+)
+
+// Indents are the valid ways to indent generated code
+var Indents = []string{IndentSpaces, IndentTab}
+
+// TypedSigils are the valid strictness levels for Sorbet's `# typed:` sigil
+var TypedSigils = []string{"ignore", "false", "true", "strict", "strong"}
+
+const (
+	// AllOfStyleFlatten generates `allOf` schemas as a single struct containing all members' properties
+	AllOfStyleFlatten = "flatten"
+	// AllOfStyleInherit generates `allOf` schemas with a single `$ref` member as a subclass of the referenced struct
+	AllOfStyleInherit = "inherit"
+)
+
+// Options configures how Generate reads the OpenAPI document, and how the Sorbet types are generated
+type Options struct {
+	// Path is the path to the OpenAPI document, `-` to read it from stdin, or an HTTP(S) URL to fetch it from. Ignored
+	// if Input is set. A glob pattern, i.e. `specs/*.yaml`, merges the schemas of each matching document, as with
+	// ExtraPaths
+	Path string
+	// ExtraPaths are the paths, glob patterns or HTTP(S) URLs, of further OpenAPI documents whose schemas are merged
+	// with those of the document at Path, or Input. Identical schemas of the same name are deduplicated, but
+	// conflicting ones are an error
+	ExtraPaths []string
+	// Input contains the contents of the OpenAPI document, if it has already been read
+	Input []byte
+	// Timeout is the timeout when fetching the OpenAPI document over HTTP(S)
+	Timeout time.Duration
+
+	// Include contains regular expressions, of which a schema's name must match at least one to be generated, if set
+	Include []string
+	// Exclude contains regular expressions, which if a schema's name matches any of, it won't be generated
+	Exclude []string
+	// Paths indicates whether to also generate types from the inline schemas of request and response bodies under
+	// `paths`, named after their operation's `operationId`, i.e. `CreateUserRequest` or `CreateUser201Response`. Bodies
+	// with different schemas per media type generate a type for each, i.e. `CreateUserRequestApplicationXml`
+	Paths bool
+	// ResponsesEnum indicates whether to also generate an enum for each operation under `paths`, of the status codes
+	// declared in its `responses`, named after the operation, i.e. `CreateUserResponses`
+	ResponsesEnum bool
+	// ReportUnused indicates whether to log the generated types which aren't referenced by any other type, or by a
+	// `$ref` from elsewhere in the document, such as an operation's response, as their schemas may no longer be needed
+	ReportUnused bool
+
+	// Strict indicates whether any diagnostics reported while parsing the schemas, such as an unsupported type, should
+	// fail generation
+	Strict bool
+
+	// Module is the `::`-separated Ruby module to generate the types within
+	Module string
+	// Out is the directory to write the generated files to, or OutStdout to write the SingleFile to stdout
+	Out string
+	// Format is the kind of files to generate, one of Formats. Defaults to FormatRB
+	Format string
+	// OutFileExt is the extension of the files that types are generated in, including the leading dot, i.e.
+	// `.gen.rb`. Defaults to the Format's extension. With FormatRB it must end in `.rb`, so the files can still be
+	// required
+	OutFileExt string
+	// Template is the path to a template file to render each type's file with, instead of the default class template,
+	// if set. It receives the same `.Metadata` and `.Type`, and can use the default templates, such as `header`, and
+	// helper functions, such as `camel`, `snake`, `pascal`, `pluralize`, `nilable` and `indent`
+	Template string
+	// NoTimestamp indicates whether to omit the time of generation from the generated files' header, so output is
+	// reproducible
+	NoTimestamp bool
+	// Jobs is the number of files to render concurrently. Defaults to GOMAXPROCS
+	Jobs int
+	// DryRun indicates whether to only log the files that would be generated, and their sizes, rather than writing them
+	DryRun bool
+	// Check indicates whether to compare the files that would be generated against those already in Out, rather than
+	// writing them, failing if any differ, are missing, or were previously generated but no longer would be. Requires
+	// NoTimestamp
+	Check bool
+	// Clean indicates whether previously generated files which are no longer generated should be removed from Out
+	Clean bool
+	// SingleFile is the name of a single file within Out to write all types to, instead of a file per type, if set
+	SingleFile string
+	// Index is the name of a file in the root of Out to write a `require_relative` for every generated file to, taking
+	// into account the Module's directories, if set
+	Index string
+	// Zeitwerk indicates whether to validate that each generated module and type would be autoloaded by Zeitwerk from
+	// the directory or file it is generated in. `types.rb` doesn't define a constant, so should be ignored by the loader
+	Zeitwerk bool
+
+	// TypedSigil is the strictness level of the `# typed:` sigil for generated files, one of TypedSigils. Defaults to
+	// `true`
+	TypedSigil string
+	// PreferTitle indicates whether a schema's `title`, if set, should be used for its class name, rather than its name.
+	// Files are still named after the schema's name
+	PreferTitle bool
+	// Inflect indicates whether the child types of inline array items should be named after the singular of the
+	// array's name, i.e. `PetTag` for the items of `Pet.tags`, rather than with an `Item` suffix, i.e. `PetTagsItem`
+	Inflect bool
+	// HoistUnions indicates whether a `T.any` union which is the type of more than one property should be generated as
+	// a named type alias, i.e. `StringOrInteger`, which the properties refer to
+	HoistUnions bool
+	// ClassCase is the case of generated class names, one of NameCases. Defaults to NameCasePascal
+	ClassCase string
+	// FileCase is the case of generated file names, one of NameCases. Defaults to NameCaseSnake
+	FileCase string
+	// NoFrozenStringLiteral indicates whether to omit the `# frozen_string_literal: true` magic comment from generated
+	// Ruby files
+	NoFrozenStringLiteral bool
+	// Int64Type is the Sorbet type to use for `format: int64` integers, if overridden
+	Int64Type string
+	// DateTimeType is the Sorbet type to use for `format: date-time` strings, if overridden. This takes precedence over
+	// any `date-time` entry in StringFormatTypes
+	DateTimeType string
+	// BinaryType is the Sorbet type to use for `format: binary` strings, such as file uploads, if overridden. This takes
+	// precedence over any `binary` entry in StringFormatTypes
+	BinaryType string
+	// ByteType is the Sorbet type to use for `format: byte`, base64-encoded, strings, if overridden. This takes
+	// precedence over any `byte` entry in StringFormatTypes
+	ByteType string
+	// UUIDType is the Sorbet type to use for `format: uuid` strings, if overridden. This takes precedence over any
+	// `uuid` entry in StringFormatTypes
+	UUIDType string
+	// DecimalType is the Sorbet type to use for `format: decimal` strings and numbers, which are arbitrary-precision to
+	// avoid the imprecision of floats. This takes precedence over any `decimal` entry in StringFormatTypes. Defaults to
+	// BigDecimal
+	DecimalType string
+	// StringFormatTypes maps the `format` of string schemas to the Sorbet type to use for them, instead of String
+	StringFormatTypes map[string]string
+	// EnumStyle is how enums should be generated, one of EnumStyleTEnum (default) or EnumStyleAlias
+	EnumStyle string
+	// AllOfStyle is how `allOf` schemas should be generated, one of AllOfStyleFlatten (default) or AllOfStyleInherit
+	AllOfStyle string
+	// AliasStyle is how schemas that aren't structs or enums, such as a `string` or an array, should be generated, one
+	// of AliasStyleType (default) or AliasStyleWrapper
+	AliasStyle string
+	// Examples indicates whether the `example` and `examples` of schemas and properties should be added to their
+	// comments
+	Examples bool
+	// Role is the kind of bodies that the types are used for, one of Roles, which determines whether `readOnly` and
+	// `writeOnly` properties are generated. Defaults to RoleBoth
+	Role string
+	// InterfaceBases indicates whether object schemas which are only used as an `allOf` base of at least two other
+	// schemas should be generated as an interface, with an abstract method for each property, which their children
+	// include, rather than a struct
+	InterfaceBases bool
+	// TypedMaps indicates whether the `additionalProperties` of objects should be keyed by String, rather than either
+	// a Symbol or String. Objects with `additionalProperties` are generated as `T::Hash` aliases, or as structs with an
+	// `additional_properties` accessor when properties are also declared
+	TypedMaps bool
+	// Mutable indicates whether structs' properties should be generated with `prop`, rather than `const`. This can be
+	// overridden per schema with the `x-sorbet-mutable` extension
+	Mutable bool
+	// Validations indicates whether structs should have a `validate!` method, enforcing the `pattern`, `minLength` and
+	// `maxLength` of string properties, the `minimum`, `maximum` and `multipleOf` of numeric properties, and that
+	// `format: uuid` properties are UUIDs, which is called when deserialized from a hash
+	Validations bool
+	// CollectionDefaults indicates whether optional array and map properties, which don't have a `default` and aren't
+	// nullable, should default to an empty collection, i.e. `default: []`, rather than being nilable
+	CollectionDefaults bool
+	// ClosedObjects indicates whether structs of objects with `additionalProperties: false` should reject any
+	// undeclared keys when deserialized from a hash, rather than ignoring them
+	ClosedObjects bool
+	// Serializers indicates whether structs should include `T::Props::Serializable`, to convert them to and from
+	// hashes using the schemas' property names
+	Serializers bool
+	// Manifest is the path to write a JSON array to, describing each generated type's schema name, type name, file,
+	// kind and properties, if set
+	Manifest string
+	// Servers indicates whether to generate a `Servers` module, with a `BASE_URL` constant of the URL of the
+	// document's first server, and a `URLS` constant of every server's URL. Server variables are left as `{name}`
+	// placeholders
+	Servers bool
+	// Fixtures is the directory to write a file of fixtures to for each type whose schema has an `example` or
+	// `examples`, if set. Each fixture is a method of a `Fixtures` module, which returns an instance of the type
+	// constructed from an example, for use in tests
+	Fixtures string
+	// ModuleStyle is how the Module is opened, one of ModuleStyles. Defaults to ModuleStyleNested
+	ModuleStyle string
+	// Indent is how generated code is indented, one of Indents. Defaults to IndentSpaces
+	Indent string
+	// LogLevel is the minimum level of messages to log, one of LogLevels. Defaults to LogLevelInfo
+	LogLevel string
+	// LogFormat is the format to log messages in, one of LogFormats. Defaults to LogFormatText
+	LogFormat string
+}
+
+// withDefaults returns a copy of the Options with any unset values defaulted
+func (o Options) withDefaults() Options {
+	if o.EnumStyle == "" {
+		o.EnumStyle = EnumStyleTEnum
+	}
+	if o.AllOfStyle == "" {
+		o.AllOfStyle = AllOfStyleFlatten
+	}
+	if o.AliasStyle == "" {
+		o.AliasStyle = AliasStyleType
+	}
+	if o.Out == "" {
+		o.Out = "out"
+	}
+	if o.Format == "" {
+		o.Format = FormatRB
+	}
+	if o.OutFileExt == "" {
+		o.OutFileExt = "." + o.Format
+	}
+	if o.Jobs <= 0 {
+		o.Jobs = runtime.GOMAXPROCS(0)
+	}
+	if o.TypedSigil == "" {
+		o.TypedSigil = "true"
+	}
+	if o.Role == "" {
+		o.Role = RoleBoth
+	}
+	if o.ModuleStyle == "" {
+		o.ModuleStyle = ModuleStyleNested
+	}
+	if o.Indent == "" {
+		o.Indent = IndentSpaces
+	}
+	if o.LogLevel == "" {
+		o.LogLevel = LogLevelInfo
+	}
+	if o.LogFormat == "" {
+		o.LogFormat = LogFormatText
+	}
+	if o.ClassCase == "" {
+		o.ClassCase = NameCasePascal
+	}
+	if o.FileCase == "" {
+		o.FileCase = NameCaseSnake
+	}
+
+	// copy, so the caller's map isn't modified
+	formatTypes := make(map[string]string, len(o.StringFormatTypes)+5)
+	for format, ty := range o.StringFormatTypes {
+		formatTypes[format] = ty
+	}
+	if o.DateTimeType != "" {
+		formatTypes["date-time"] = o.DateTimeType
+	}
+	if o.BinaryType != "" {
+		formatTypes["binary"] = o.BinaryType
+	}
+	if o.ByteType != "" {
+		formatTypes["byte"] = o.ByteType
+	}
+	if o.UUIDType != "" {
+		formatTypes["uuid"] = o.UUIDType
+	}
+	if o.DecimalType == "" {
+		o.DecimalType = "BigDecimal"
+		if ty, ok := formatTypes["decimal"]; ok {
+			o.DecimalType = ty
+		}
+	}
+	formatTypes["decimal"] = o.DecimalType
+	o.StringFormatTypes = formatTypes
+
+	return o
+}
+
+func (o Options) validate() error {
+	if o.EnumStyle != EnumStyleTEnum && o.EnumStyle != EnumStyleAlias {
+		return fmt.Errorf("invalid EnumStyle %#v, expected one of %#v or %#v", o.EnumStyle, EnumStyleTEnum, EnumStyleAlias)
+	}
+
+	if o.AllOfStyle != AllOfStyleFlatten && o.AllOfStyle != AllOfStyleInherit {
+		return fmt.Errorf("invalid AllOfStyle %#v, expected one of %#v or %#v", o.AllOfStyle, AllOfStyleFlatten, AllOfStyleInherit)
+	}
+
+	if o.AliasStyle != AliasStyleType && o.AliasStyle != AliasStyleWrapper {
+		return fmt.Errorf("invalid AliasStyle %#v, expected one of %#v or %#v", o.AliasStyle, AliasStyleType, AliasStyleWrapper)
+	}
+
+	if !slices.Contains(TypedSigils, o.TypedSigil) {
+		return fmt.Errorf("invalid TypedSigil %#v, expected one of %#v", o.TypedSigil, TypedSigils)
+	}
+
+	if !slices.Contains(NameCases, o.ClassCase) {
+		return fmt.Errorf("invalid ClassCase %#v, expected one of %#v", o.ClassCase, NameCases)
+	}
+
+	if !slices.Contains(NameCases, o.FileCase) {
+		return fmt.Errorf("invalid FileCase %#v, expected one of %#v", o.FileCase, NameCases)
+	}
+
+	if !slices.Contains(Roles, o.Role) {
+		return fmt.Errorf("invalid Role %#v, expected one of %#v", o.Role, Roles)
+	}
+
+	if !slices.Contains(ModuleStyles, o.ModuleStyle) {
+		return fmt.Errorf("invalid ModuleStyle %#v, expected one of %#v", o.ModuleStyle, ModuleStyles)
+	}
+
+	if !slices.Contains(Indents, o.Indent) {
+		return fmt.Errorf("invalid Indent %#v, expected one of %#v", o.Indent, Indents)
+	}
+
+	if !slices.Contains(LogLevels, o.LogLevel) {
+		return fmt.Errorf("invalid LogLevel %#v, expected one of %#v", o.LogLevel, LogLevels)
+	}
+
+	if !slices.Contains(LogFormats, o.LogFormat) {
+		return fmt.Errorf("invalid LogFormat %#v, expected one of %#v", o.LogFormat, LogFormats)
+	}
+
+	if !slices.Contains(Formats, o.Format) {
+		return fmt.Errorf("invalid Format %#v, expected one of %#v", o.Format, Formats)
+	}
+
+	if !strings.HasPrefix(o.OutFileExt, ".") || len(o.OutFileExt) == 1 || strings.ContainsAny(o.OutFileExt, `/\`) {
+		return fmt.Errorf("invalid OutFileExt %#v, expected an extension starting with a dot, i.e. \".rb\"", o.OutFileExt)
+	}
+
+	if o.Format == FormatRB && !strings.HasSuffix(o.OutFileExt, ".rb") {
+		if ext := strings.TrimPrefix(filepath.Ext(o.OutFileExt), "."); ext != FormatRB && slices.Contains(Formats, ext) {
+			return fmt.Errorf("the OutFileExt %#v must end in .rb with the %s Format, so the generated files can be required. To generate %s files, use the %s Format, i.e. `-format %s`", o.OutFileExt, o.Format, o.OutFileExt, ext, ext)
+		}
+		return fmt.Errorf("the OutFileExt %#v must end in .rb with the %s Format, so the generated files can be required", o.OutFileExt, o.Format)
+	}
+
+	if o.Zeitwerk && o.OutFileExt != ".rb" {
+		return fmt.Errorf("Zeitwerk validation requires the .rb OutFileExt, as Zeitwerk only autoloads .rb files named after their constant")
+	}
+
+	if o.Format != FormatRB && o.Index != "" {
+		return fmt.Errorf("an Index cannot be used with the %s Format, as only Ruby files are required", o.Format)
+	}
+
+	if o.Out == OutStdout && (o.SingleFile == "" || o.Index != "" || o.Clean || o.DryRun || o.Check) {
+		return fmt.Errorf("writing to stdout requires SingleFile, and cannot be used with an Index, Clean, DryRun or Check, as no files are written")
+	}
+
+	if o.Servers && o.Out == OutStdout {
+		return fmt.Errorf("Servers cannot be used when writing to stdout, as they're generated in their own file")
+	}
+
+	if o.Fixtures != "" && (o.Format != FormatRB || o.Out == OutStdout) {
+		return fmt.Errorf("Fixtures can only be generated with the %s Format, and when writing files, as they require the generated types", FormatRB)
+	}
+
+	if o.DryRun && o.Check {
+		return fmt.Errorf("DryRun and Check cannot be used together")
+	}
+
+	if o.Check && !o.NoTimestamp {
+		return fmt.Errorf("Check requires NoTimestamp, as the time of generation in the header would always differ")
+	}
+
+	if o.Zeitwerk && o.SingleFile != "" {
+		return fmt.Errorf("Zeitwerk validation cannot be used with SingleFile, as Zeitwerk requires a file per type")
+	}
+
+	if o.Path == "" && o.Input == nil {
+		return fmt.Errorf("no OpenAPI document was provided, either through Path or Input")
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+)
+
+// fileWriter writes the generated files to disk, unless dryRun is set, in which case each file that would be written
+// is logged instead, or check is set, in which case each file is compared against the existing file instead, so
+// generation can be checked without modifying the output directory
+type fileWriter struct {
+	dryRun bool
+	check  bool
+	// tabs indicates that rendered files should be indented by tabs, rather than the templates' two spaces
+	tabs bool
+	// log is the logger that the files written are logged to
+	log *logger
+
+	// mu guards the fields below, as files are written concurrently
+	mu sync.Mutex
+	// written contains the path of each file written, or that would have been when dry running or checking
+	written map[string]bool
+	// unchanged counts the files that weren't rewritten, as their contents were already up to date
+	unchanged int
+	// differences describes each file that differs from the existing file, when checking
+	differences []string
+}
+
+// mkdirAll creates the directory, and any parents, that files will be written to
+func (w *fileWriter) mkdirAll(dir string) error {
+	if w.dryRun || w.check {
+		return nil
+	}
+	return os.MkdirAll(dir, os.ModePerm)
+}
+
+// writeFile writes the contents of a generated file. Files whose contents are already up to date aren't rewritten, so
+// their modification times are preserved
+func (w *fileWriter) writeFile(path string, contents []byte) error {
+	w.record(path)
+
+	if w.dryRun {
+		w.log.infof("Would write %s (%d bytes)", path, len(contents))
+		return nil
+	}
+	if w.check {
+		return w.compare(path, contents)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, contents) {
+		w.log.debugf("Skipping writing %s, as it's unchanged", path)
+		w.mu.Lock()
+		w.unchanged++
+		w.mu.Unlock()
+		return nil
+	}
+	return os.WriteFile(path, contents, 0o644)
+}
+
+// record records that a generated file has been written, so it isn't reported or removed as no longer generated
+func (w *fileWriter) record(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.written == nil {
+		w.written = make(map[string]bool)
+	}
+	w.written[path] = true
+}
+
+// logUnchanged logs how many files weren't rewritten, as their contents were already up to date
+func (w *fileWriter) logUnchanged() {
+	if w.unchanged > 0 {
+		w.log.infof("Skipped writing %d unchanged files", w.unchanged)
+	}
+}
+
+// compare records whether the contents of a generated file differ from the existing file
+func (w *fileWriter) compare(path string, contents []byte) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err != nil {
+		w.differences = append(w.differences, fmt.Sprintf("%s is missing", path))
+	} else if !bytes.Equal(existing, contents) {
+		w.differences = append(w.differences, fmt.Sprintf("%s is stale, first differing at line %d", path, firstDifferentLine(string(existing), string(contents))))
+	}
+	return nil
+}
+
+// checkResult returns an error describing each file that differs, is missing, or was previously generated in dir but no
+// longer would be, if any. Files with the given extension are checked as well as `.rb`, `.rbi` and `.rbs` files
+func (w *fileWriter) checkResult(dir string, ext string) error {
+	files, err := generatedFiles(dir, ext)
+	if err != nil {
+		return err
+	}
+
+	differences := w.differences
+	for _, path := range files {
+		if !w.written[path] {
+			differences = append(differences, fmt.Sprintf("%s would no longer be generated", path))
+		}
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+
+	slices.Sort(differences)
+	return fmt.Errorf("%d generated files differ from those in %s:\n  %s", len(differences), dir, strings.Join(differences, "\n  "))
+}
+
+// firstDifferentLine returns the 1-indexed number of the first line that differs between a and b
+func firstDifferentLine(a string, b string) int {
+	aLines := strings.Split(a, "\n")
+	bLines := strings.Split(b, "\n")
+	for i := range aLines {
+		if i >= len(bLines) || aLines[i] != bLines[i] {
+			return i + 1
+		}
+	}
+	return len(aLines) + 1
+}
+
+// remove removes a previously generated file
+func (w *fileWriter) remove(path string) error {
+	if w.dryRun {
+		w.log.infof("Would remove previously generated %s", path)
+		return nil
+	}
+	if w.check {
+		// any file that would no longer be generated is reported by checkResult
+		return nil
+	}
+	w.log.infof("Removing previously generated %s", path)
+	return os.Remove(path)
+}
+
+// indented returns the rendered contents of a file, indented by tabs rather than two spaces if configured. Block
+// comments are left as they are, as their indentation is part of the comment
+func (w *fileWriter) indented(contents string) string {
+	if !w.tabs {
+		return contents
+	}
+
+	lines := strings.Split(contents, "\n")
+	inComment := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "=begin"):
+			inComment = true
+		case strings.HasPrefix(line, "=end"):
+			inComment = false
+		case !inComment:
+			trimmed := strings.TrimLeft(line, " ")
+			depth := (len(line) - len(trimmed)) / 2
+			lines[i] = strings.Repeat("\t", depth) + line[depth*2:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// generated logs that a file was generated, which isn't logged for a dry run or check, as no files are written
+func (w *fileWriter) generated(format string, args ...any) {
+	if w.dryRun || w.check {
+		return
+	}
+	w.log.infof(format, args...)
+}
@@ -0,0 +1,1759 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// parser converts OpenAPI schemas to the Types to generate, according to the Options
+type parser struct {
+	opts Options
+	// log is the logger that decisions made while parsing, and diagnostics, are logged to
+	log *logger
+
+	// visiting contains the type names of the schemas currently being parsed, to detect circular references
+	visiting map[string]bool
+	// diagnostics contains the problems found while parsing
+	diagnostics []Diagnostic
+	// extensionTypes contains the types referenced through the `x-sorbet-type` extension, or skipped through the
+	// `x-sorbet-skip` extension, which are provided by the consuming codebase, rather than generated
+	extensionTypes map[string]bool
+	// interfaceBases contains the names of the types which are generated as an interface, rather than a struct, as
+	// they're only used as an `allOf` base
+	interfaceBases map[string]bool
+	// typeFiles maps the name of each type to the file it is generated in, so other types can require it
+	typeFiles map[string]string
+	// titles maps the name of each schema to its `title`, if it should be used for its class name
+	titles map[string]string
+	// refs caches the schemas that `$ref`s resolve to, and the types that they're parsed as
+	refs refCache
+	// document is the path to the document that the schema being parsed was read from, as a `$ref` is resolved within
+	// its own document
+	document string
+	// componentTypes contains the type names of the component schemas, which inline array items can't be named after
+	// when Inflect is enabled
+	componentTypes map[string]bool
+}
+
+// sorbetTypeExtension is the vendor extension to override the Sorbet type of a schema or property
+const sorbetTypeExtension = "x-sorbet-type"
+
+// sorbetSkipExtension is the vendor extension to skip generating a schema, such as when its type is hand-maintained
+const sorbetSkipExtension = "x-sorbet-skip"
+
+// sorbetMutableExtension is the vendor extension to generate an object schema's properties as mutable
+const sorbetMutableExtension = "x-sorbet-mutable"
+
+// enumVarNamesExtension is the vendor extension, popularised by OpenAPI Generator, containing the name of each of an
+// enum's values, in the same order as the `enum`
+const enumVarNamesExtension = "x-enum-varnames"
+
+// enumDescriptionsExtension is the vendor extension, popularised by OpenAPI Generator, containing the description of
+// each of an enum's values, in the same order as the `enum`
+const enumDescriptionsExtension = "x-enum-descriptions"
+
+// notComment explains why a `not` schema, which only describes what a value can't be, is untyped
+const notComment = "Any value which doesn't match a `not` schema, which Sorbet can't express, so is untyped"
+
+// parseComponent parses a top-level schema from `#/components/schemas`
+func (p *parser) parseComponent(name string, v *base.Schema) []Type {
+	p.visiting = map[string]bool{
+		p.typeName(name): true,
+	}
+
+	return p.parseSchema(name, v)
+}
+
+// schema returns the schema that sp resolves to, resolving each `$ref` to a generated type only once
+func (p *parser) schema(sp *base.SchemaProxy) *base.Schema {
+	return p.refs.schema(p.document, sp)
+}
+
+// parseRef parses the schema that sp resolves to with parse, parsing each `$ref` which isn't to a generated type only
+// once, so that each of its occurrences refers to the same type, rather than generating a copy of the type for each.
+// generated is false when the types have already been generated for an earlier occurrence
+func (p *parser) parseRef(sp *base.SchemaProxy, parse func() (string, []Type)) (typeName string, types []Type, generated bool) {
+	return p.refs.parse(p.document, sp, parse)
+}
+
+// parseRefAlias parses a top-level schema which is only a `$ref` to another schema, as a constant referring to the
+// referenced type, so it can be used in the same way
+func (p *parser) parseRefAlias(name string, ref string) Type {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Alias = p.refTypeName(ref)
+	t.IsRefAlias = true
+	return t
+}
+
+// resolveRefAliases generates each `$ref` alias whose referenced type is itself a type alias as a type alias, rather
+// than a constant, as a type alias isn't a class
+func resolveRefAliases(types []Type) {
+	byName := make(map[string]Type, len(types))
+	for _, t := range types {
+		byName[t.TypeName] = t
+	}
+
+	var isClass func(name string, depth int) bool
+	isClass = func(name string, depth int) bool {
+		t, ok := byName[name]
+		if !ok || depth > len(types) {
+			// a type that isn't generated is assumed to be a class, as is a circular chain of aliases
+			return true
+		}
+		if t.IsRefAlias {
+			return isClass(t.Alias, depth+1)
+		}
+		return !t.IsAlias() || t.IsWrapper
+	}
+
+	for i, t := range types {
+		if t.IsRefAlias && !isClass(t.Alias, 0) {
+			types[i].IsRefAlias = false
+		}
+	}
+}
+
+// typeName returns the Ruby constant name for a schema, according to the ClassCase, and records the file it's generated
+// in according to the FileCase
+func (p *parser) typeName(name string) string {
+	n := typeName(name, p.opts.ClassCase)
+	file := fileName(name, n, p.opts.FileCase)
+	if title, ok := p.titles[name]; ok {
+		// the file is still named after the schema, so it can be found from the schema's name
+		n = typeName(title, p.opts.ClassCase)
+	}
+
+	if p.typeFiles == nil {
+		p.typeFiles = make(map[string]string)
+	}
+	p.typeFiles[n] = file
+	return n
+}
+
+// fileName returns the name of the file, without extension, that a schema's type is generated in
+func (p *parser) fileName(name string) string {
+	return p.typeFiles[p.typeName(name)]
+}
+
+// relativeRequires returns the files of the other generated types that the Type references
+func (p *parser) relativeRequires(t Type) []string {
+	required := make(map[string]bool)
+
+	sorbetTypes := []string{t.Alias, t.AdditionalProperties}
+	sorbetTypes = append(sorbetTypes, t.Union...)
+	sorbetTypes = append(sorbetTypes, t.Interfaces...)
+	for _, prop := range t.Properties {
+		sorbetTypes = append(sorbetTypes, prop.Type)
+	}
+
+	if t.IsObject() {
+		sorbetTypes = append(sorbetTypes, t.BaseClass)
+	}
+
+	for _, ty := range sorbetTypes {
+		for _, ref := range p.referencedTypes(ty) {
+			if ref == t.TypeName {
+				continue
+			}
+			filename, ok := p.typeFiles[ref]
+			if !ok {
+				filename = strcase.ToSnake(ref)
+			}
+			required[filename] = true
+		}
+	}
+
+	result := make([]string, 0, len(required))
+	for filename := range required {
+		result = append(result, "./"+filename)
+	}
+
+	slices.Sort(result)
+	return result
+}
+
+// isBuiltinType reports whether ty is provided by Ruby or Sorbet, or has been configured through the Options, and
+// therefore doesn't need a `require_relative`
+func (p *parser) isBuiltinType(ty string) bool {
+	switch ty {
+	case SorbetUntyped, "String", "Integer", "Float", "T::Boolean":
+		return true
+	}
+	if strings.HasPrefix(ty, "T.") || strings.HasPrefix(ty, "T::") {
+		return true
+	}
+	if p.extensionTypes[ty] {
+		return true
+	}
+	for _, formatType := range p.opts.StringFormatTypes {
+		if ty == formatType {
+			return true
+		}
+	}
+	return ty == p.opts.Int64Type
+}
+
+// referencedTypes returns the types referenced by a Sorbet type that aren't builtin, i.e. `T.nilable(T::Array[Pet])`
+// references `Pet`
+func (p *parser) referencedTypes(ty string) (refs []string) {
+	names := strings.FieldsFunc(ty, func(r rune) bool {
+		return strings.ContainsRune("[](), ", r)
+	})
+	for _, name := range names {
+		if !p.isBuiltinType(name) {
+			refs = append(refs, name)
+		}
+	}
+	return
+}
+
+// prepareComment tidies a `description` to be rendered as a comment, ensuring that no line can end the `=begin`
+// block comment that it's rendered within
+func prepareComment(s string) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n")), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "=end") {
+			lines[i] = " " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// appendComment appends a paragraph to an existing, possibly empty, comment
+func appendComment(comment string, paragraph string) string {
+	if paragraph == "" {
+		return comment
+	}
+	if comment == "" {
+		return paragraph
+	}
+	return comment + "\n\n" + paragraph
+}
+
+// exampleComment returns a comment containing a schema's `example` and `examples`, each as compact JSON, i.e.
+// `Example: {"name":"Rex"}`
+func exampleComment(v *base.Schema) string {
+	examples := v.Examples
+	if v.Example != nil {
+		examples = append([]any{v.Example}, examples...)
+	}
+
+	var lines []string
+	for _, example := range examples {
+		b, err := json.Marshal(example)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("Example: %v", example))
+			continue
+		}
+		lines = append(lines, "Example: "+string(b))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *parser) parseString(name string, v *base.Schema) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.Alias = p.stringType(v)
+	t.Comment = appendComment(t.Comment, binaryFormats[v.Format])
+	t.Comment = appendComment(t.Comment, p.decimalComment(v))
+	t.IsStringEnum = true
+
+	// an empty `enum` allows no values, which can't be expressed, so is treated as if it weren't set
+	if len(v.Enum) > 0 {
+		p.applyEnum(&t, name, v)
+	}
+	nilableAlias(&t, v)
+
+	types = append(types, t)
+	return types
+}
+
+// nilableAlias makes the alias of a scalar schema `T.nilable`, if the schema is `nullable`
+func nilableAlias(t *Type, v *base.Schema) {
+	if v.Nullable != nil && *v.Nullable {
+		t.Nilable = true
+	}
+}
+
+// extensionType returns the Sorbet type set through the schema's `x-sorbet-type` extension, or false if it isn't set
+func (p *parser) extensionType(name string, property string, v *base.Schema) (string, bool) {
+	ext, ok := v.Extensions[sorbetTypeExtension]
+	if !ok {
+		return "", false
+	}
+
+	ty, ok := ext.(string)
+	if !ok || ty == "" {
+		p.report(DiagnosticWarning, name, property, "has an invalid %s extension (`  %v `), which must be a non-empty string, so will be ignored", sorbetTypeExtension, ext)
+		return "", false
+	}
+
+	if p.extensionTypes == nil {
+		p.extensionTypes = make(map[string]bool)
+	}
+	for _, ref := range p.referencedTypes(ty) {
+		p.extensionTypes[ref] = true
+	}
+
+	p.log.infof("%s has the %s extension, so will be generated as %s instead of its inferred type", schemaPath(name, property), sorbetTypeExtension, ty)
+	return ty, true
+}
+
+// skipExtension reports whether the schema has the `x-sorbet-skip` extension, in which case it isn't generated, and
+// references to it use its type name without requiring it, as the type is provided by the consuming codebase
+func (p *parser) skipExtension(name string, v *base.Schema) bool {
+	skip, ok := v.Extensions[sorbetSkipExtension].(bool)
+	if !ok || !skip {
+		return false
+	}
+
+	if p.extensionTypes == nil {
+		p.extensionTypes = make(map[string]bool)
+	}
+	p.extensionTypes[p.typeName(name)] = true
+
+	p.log.infof("%s has the %s extension, so won't be generated", name, sorbetSkipExtension)
+	return true
+}
+
+// binaryFormats describes the `format`s of string schemas which contain binary data, rather than text
+var binaryFormats = map[string]string{
+	"binary": "Binary data, such as a file upload",
+	"byte":   "Base64-encoded binary data",
+}
+
+// decimalComment returns a comment noting that `bigdecimal` must be required, when the schema is a `format: decimal`
+// string or number that's generated as a BigDecimal, which Ruby doesn't load by default
+func (p *parser) decimalComment(v *base.Schema) string {
+	if v.Format != "decimal" || p.opts.DecimalType != "BigDecimal" {
+		return ""
+	}
+	return "An arbitrary-precision decimal, which needs `require 'bigdecimal'`"
+}
+
+// stringType returns the Sorbet type for a `string` schema, taking into account its `format`
+func (p *parser) stringType(v *base.Schema) string {
+	if ty, ok := p.opts.StringFormatTypes[v.Format]; ok {
+		return ty
+	}
+	return "String"
+}
+
+// applyEnum populates the Enum values for the given Type from the schema's `enum`, converting them to their Ruby
+// literal form. A `null` member isn't a value of the enum, but instead makes the Type nilable
+func (p *parser) applyEnum(t *Type, name string, v *base.Schema) {
+	seen := make(map[string]int)
+	varNames := p.enumExtension(name, v, enumVarNamesExtension)
+	descriptions := p.enumExtension(name, v, enumDescriptionsExtension)
+	for i, enum := range v.Enum {
+		var val string
+		switch e := enum.(type) {
+		case nil:
+			t.Nilable = true
+			continue
+		case string:
+			if !t.IsStringEnum {
+				p.report(DiagnosticWarning, name, "", "has a string enum value (`  %s `) for a non-string type, which will be skipped", e)
+				continue
+			}
+			val = e
+		case bool, int, int64, float64:
+			if t.IsStringEnum {
+				p.report(DiagnosticWarning, name, "", "has a non-string enum type (`  %s `), which failed to have its type converted to a string", reflect.TypeOf(enum).String())
+				continue
+			}
+			val = enumLiteral(e, t.Alias)
+		default:
+			p.report(DiagnosticWarning, name, "", "has an unsupported enum type (`  %T `), which will be skipped", enum)
+			continue
+		}
+
+		literal := val
+		if t.IsStringEnum {
+			literal = rubyString(val)
+		}
+
+		n := enumName(val, seen)
+		if i < len(varNames) && varNames[i] != "" {
+			n = uniqueEnumName(varNames[i], seen)
+		}
+
+		var comment string
+		if i < len(descriptions) {
+			comment = strings.TrimSpace(descriptions[i])
+		}
+
+		t.Enum = append(t.Enum, Enum{
+			Name:    n,
+			Value:   val,
+			Literal: literal,
+			Comment: comment,
+		})
+	}
+
+	if len(t.Enum) > 0 && p.opts.EnumStyle == EnumStyleTEnum {
+		t.BaseClass = "T::Enum"
+	}
+}
+
+// enumExtension returns the values of a vendor extension which is an array in the same order as the schema's `enum`,
+// such as enumVarNamesExtension, or nil if it isn't set. A warning is reported if the lengths differ, in which case
+// only the values which are present are used
+func (p *parser) enumExtension(name string, v *base.Schema, extension string) []string {
+	ext, ok := v.Extensions[extension]
+	if !ok {
+		return nil
+	}
+
+	items, ok := ext.([]any)
+	if !ok {
+		p.report(DiagnosticWarning, name, "", "has an invalid %s extension (`  %v `), which must be an array, so will be ignored", extension, ext)
+		return nil
+	}
+	if len(items) != len(v.Enum) {
+		p.report(DiagnosticWarning, name, "", "has %d values in its %s extension, but %d enum values", len(items), extension, len(v.Enum))
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		if item != nil {
+			values[i] = fmt.Sprint(item)
+		}
+	}
+	return values
+}
+
+// enumLiteral returns the Ruby literal for a non-string enum value, ensuring that `Float` values are always rendered
+// as floating point numbers
+func enumLiteral(v any, alias string) string {
+	switch e := v.(type) {
+	case int:
+		v = int64(e)
+	case float64:
+		s := strconv.FormatFloat(e, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	}
+
+	if i, ok := v.(int64); ok && alias == "Float" {
+		return strconv.FormatInt(i, 10) + ".0"
+	}
+
+	return fmt.Sprint(v)
+}
+
+// defaultLiteral returns the Ruby literal for the schema's `default`, or false if no default is set, or it can't be
+// represented
+func (p *parser) defaultLiteral(name string, property string, v *base.Schema, alias string) (string, bool) {
+	// a `default: null` is only distinguishable from an unset `default` through the low-level model
+	if v.GoLow() == nil || v.GoLow().Default.IsEmpty() {
+		return "", false
+	}
+
+	lit, ok := rubyLiteral(v.Default, alias)
+	if !ok {
+		p.report(DiagnosticWarning, name, property, "has an unsupported default (`  %v `), which will be ignored", v.Default)
+	}
+	return lit, ok
+}
+
+// refDefault returns the Ruby literal for the `default` alongside a property's `$ref`, or an empty string if it can't
+// be represented. A default of an enum is deserialized into its value, i.e. `Status.deserialize('active')`
+func (p *parser) refDefault(name string, property string, ref *base.SchemaProxy, prop Property, v any) string {
+	if v == nil {
+		if prop.Required && !prop.Nullable {
+			p.report(DiagnosticWarning, name, property, "has a `null` default, but isn't nullable, so it will be ignored")
+			return ""
+		}
+		return "nil"
+	}
+
+	schema := p.schema(ref)
+	if schema == nil {
+		return ""
+	}
+	if isStructSchema(schema) {
+		p.report(DiagnosticWarning, name, property, "has a default, but is generated as a struct, so it will be ignored")
+		return ""
+	}
+
+	alias := ""
+	if slices.Contains(schema.Type, "number") {
+		alias = "Float"
+	}
+	lit, ok := rubyLiteral(v, alias)
+	if !ok {
+		p.report(DiagnosticWarning, name, property, "has an unsupported default (`  %v `), which will be ignored", v)
+		return ""
+	}
+
+	if len(schema.Enum) > 0 && p.opts.EnumStyle == EnumStyleTEnum {
+		if slices.IndexFunc(schema.Enum, func(e any) bool { return e != nil && fmt.Sprint(e) == fmt.Sprint(v) }) < 0 {
+			p.report(DiagnosticWarning, name, property, "has a default (`  %s `) which isn't one of its enum values, so it will be ignored", lit)
+			return ""
+		}
+		return fmt.Sprintf("%s.deserialize(%s)", prop.Type, lit)
+	}
+
+	if p.opts.AliasStyle == AliasStyleWrapper {
+		p.report(DiagnosticWarning, name, property, "has a default, but is generated as a wrapper class, so it will be ignored")
+		return ""
+	}
+
+	return lit
+}
+
+// rubyLiteral returns the Ruby literal for a value decoded from the document, or false if it can't be represented.
+// Only empty objects are supported, as an object's type will be a T::Struct
+func rubyLiteral(v any, alias string) (string, bool) {
+	switch e := v.(type) {
+	case nil:
+		return "nil", true
+	case string:
+		return rubyString(e), true
+	case bool, int, int64, float64:
+		return enumLiteral(e, alias), true
+	case []any:
+		items := make([]string, 0, len(e))
+		for _, item := range e {
+			lit, ok := rubyLiteral(item, alias)
+			if !ok {
+				return "", false
+			}
+			items = append(items, lit)
+		}
+		return "[" + strings.Join(items, ", ") + "]", true
+	case map[string]any:
+		if len(e) == 0 {
+			return "{}", true
+		}
+	}
+	return "", false
+}
+
+// rubyRegexp returns the Ruby literal for a regular expression `pattern`, escaping any characters that would end the
+// literal or be interpolated
+func rubyRegexp(pattern string) string {
+	var sb strings.Builder
+	escaped := false
+	for i, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '/':
+			sb.WriteRune('\\')
+		case r == '#' && strings.HasPrefix(pattern[i:], "#{"):
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return "/" + sb.String() + "/"
+}
+
+// rubyString returns the single-quoted Ruby literal for the string
+func rubyString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+// enumName returns the Ruby constant name for an enum value, suffixing a counter if the name has already been seen
+// i.e. when values such as `foo-bar` and `foo_bar` collide after camel-casing
+func enumName(val string, seen map[string]int) string {
+	return uniqueEnumName(strcase.ToCamel(val), seen)
+}
+
+// uniqueEnumName returns a valid Ruby constant name for an enum value from the given name, suffixed with a number if
+// the name has already been seen
+func uniqueEnumName(name string, seen map[string]int) string {
+	n := invalidIdentifierChars.ReplaceAllString(capitalize(name), "")
+	if n == "" || !unicode.IsUpper([]rune(n)[0]) {
+		// constants must begin with an uppercase letter, i.e. for numeric values
+		n = "Value" + n
+	}
+	seen[n]++
+	if seen[n] > 1 {
+		return fmt.Sprintf("%s%d", n, seen[n])
+	}
+	return n
+}
+
+func (p *parser) parseBoolean(name string, v *base.Schema) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.Alias = "T::Boolean"
+	p.applyEnum(&t, name, v)
+	nilableAlias(&t, v)
+
+	types = append(types, t)
+	return
+}
+
+func (p *parser) parseNumber(name string, v *base.Schema) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.Alias = p.numberType(name, "", v)
+	t.Comment = appendComment(t.Comment, p.decimalComment(v))
+	t.Numeric = numericConstraints(v)
+	if t.Numeric != nil {
+		t.Comment = appendComment(t.Comment, t.Numeric.String())
+	}
+	p.applyEnum(&t, name, v)
+	nilableAlias(&t, v)
+
+	types = append(types, t)
+	return
+}
+
+func (p *parser) parseInteger(name string, v *base.Schema) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.Alias = p.integerType(name, "", v)
+	t.Numeric = numericConstraints(v)
+	if t.Numeric != nil {
+		t.Comment = appendComment(t.Comment, t.Numeric.String())
+	}
+	p.applyEnum(&t, name, v)
+	nilableAlias(&t, v)
+
+	types = append(types, t)
+	return
+}
+
+// numericConstraints returns the constraints of an `integer` or `number` schema, or nil if it has none. An
+// `exclusiveMinimum` or `exclusiveMaximum` may either be a boolean, as in OpenAPI 3.0, modifying the `minimum` or
+// `maximum`, or the bound itself, as in OpenAPI 3.1
+func numericConstraints(v *base.Schema) *NumericConstraints {
+	// the high-level model reads the constraints as integers, truncating those of `number` schemas, so they're instead
+	// read from the document as written
+	low := v.GoLow()
+	if low == nil {
+		return nil
+	}
+
+	c := NumericConstraints{
+		Minimum:    numberLiteral(low.Minimum.ValueNode),
+		Maximum:    numberLiteral(low.Maximum.ValueNode),
+		MultipleOf: numberLiteral(low.MultipleOf.ValueNode),
+	}
+
+	if e := v.ExclusiveMinimum; e != nil && e.IsA() {
+		c.ExclusiveMinimum = e.A && c.Minimum != ""
+	} else if e != nil {
+		c.Minimum = numberLiteral(low.ExclusiveMinimum.ValueNode)
+		c.ExclusiveMinimum = c.Minimum != ""
+	}
+	if e := v.ExclusiveMaximum; e != nil && e.IsA() {
+		c.ExclusiveMaximum = e.A && c.Maximum != ""
+	} else if e != nil {
+		c.Maximum = numberLiteral(low.ExclusiveMaximum.ValueNode)
+		c.ExclusiveMaximum = c.Maximum != ""
+	}
+
+	if c.Minimum == "" && c.Maximum == "" && c.MultipleOf == "" {
+		return nil
+	}
+	return &c
+}
+
+// numberLiteral returns the number in a YAML node as a Ruby literal, i.e. `0.5` or `100`, or an empty string if the
+// node isn't set or isn't a number. Integers are kept as written, so they aren't rounded as a float
+func numberLiteral(node *yaml.Node) json.Number {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	if _, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+		return json.Number(node.Value)
+	}
+	f, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return ""
+	}
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// numberType returns the Sorbet type for a `number` schema, taking into account its `format`
+func (p *parser) numberType(name string, property string, v *base.Schema) string {
+	switch v.Format {
+	case "", "float", "double":
+	case "decimal":
+		return p.opts.DecimalType
+	default:
+		p.report(DiagnosticWarning, name, property, "has an unknown number format (`  %s `), which will be treated as a Float", v.Format)
+	}
+
+	return "Float"
+}
+
+// integerType returns the Sorbet type for an `integer` schema, taking into account its `format`
+func (p *parser) integerType(name string, property string, v *base.Schema) string {
+	switch v.Format {
+	case "", "int32":
+	case "int64":
+		if p.opts.Int64Type != "" {
+			return p.opts.Int64Type
+		}
+	default:
+		p.report(DiagnosticWarning, name, property, "has an unknown integer format (`  %s `), which will be treated as an Integer", v.Format)
+	}
+
+	return "Integer"
+}
+
+// collectProperties returns the properties, and names of the required properties, of an object schema, merging in the
+// properties of any `allOf` members. When a property is defined multiple times, the last definition wins
+func (p *parser) collectProperties(name string, v *base.Schema) (properties map[string]*base.SchemaProxy, required []string, siblings map[string]refSiblings) {
+	properties = make(map[string]*base.SchemaProxy)
+	siblings = make(map[string]refSiblings)
+
+	merge := func(other map[string]*base.SchemaProxy, otherSiblings map[string]refSiblings) {
+		for _, propertyName := range sortedKeys(other) {
+			sp := other[propertyName]
+			if _, ok := properties[propertyName]; ok {
+				p.report(DiagnosticWarning, name, "", "has multiple definitions of property %s through allOf, the last of which will be used", propertyName)
+			}
+			properties[propertyName] = sp
+
+			delete(siblings, propertyName)
+			if s, ok := otherSiblings[propertyName]; ok {
+				siblings[propertyName] = s
+			}
+		}
+	}
+
+	for i, sp := range v.AllOf {
+		var ref string
+		if isSchemaRef(sp) {
+			ref = p.refTypeName(sp.GetReference())
+			if p.visiting[ref] {
+				p.report(DiagnosticWarning, name, "", "has a circular reference to %s through allOf, which will be skipped", ref)
+				continue
+			}
+		}
+
+		schema := p.schema(sp)
+		if schema == nil {
+			p.report(DiagnosticSkipped, name, "", "had an unresolvable allOf member %d, which will be skipped: %v", i+1, sp.GetBuildError())
+			continue
+		}
+
+		if ref != "" {
+			p.visiting[ref] = true
+		}
+
+		memberProperties, memberRequired, memberSiblings := p.collectProperties(name, schema)
+		merge(memberProperties, memberSiblings)
+		required = append(required, memberRequired...)
+
+		delete(p.visiting, ref)
+	}
+
+	merge(v.Properties, propertyRefSiblings(v))
+	required = append(required, v.Required...)
+
+	return
+}
+
+// inheritedBase determines whether an `allOf` schema has a single `$ref` member that is an object, which can be
+// inherited from. If so, the parent's class name is returned, alongside a schema containing only the local properties
+func (p *parser) inheritedBase(name string, v *base.Schema) (parent string, local *base.Schema, ok bool) {
+	local = &base.Schema{
+		Properties: v.Properties,
+		Required:   v.Required,
+	}
+
+	var ref *base.SchemaProxy
+	for _, sp := range v.AllOf {
+		// an interface can't be inherited from, so its properties are flattened instead
+		if !isSchemaRef(sp) || p.interfaceBases[p.refTypeName(sp.GetReference())] {
+			local.AllOf = append(local.AllOf, sp)
+			continue
+		}
+
+		if ref != nil {
+			// multiple `$ref`s can't be expressed through inheritance
+			return "", nil, false
+		}
+		ref = sp
+	}
+
+	if ref == nil {
+		return "", nil, false
+	}
+
+	if !isStructSchema(p.schema(ref)) {
+		p.report(DiagnosticWarning, name, "", "has an allOf base %s that is not an object, so its properties will be flattened instead", ref.GetReference())
+		return "", nil, false
+	}
+
+	return p.refTypeName(ref.GetReference()), local, true
+}
+
+// isStructSchema reports whether the schema will be generated as a `T::Struct`
+func isStructSchema(v *base.Schema) bool {
+	if v == nil {
+		return false
+	}
+
+	if len(v.Type) == 0 {
+		return len(v.AllOf) > 0 || len(v.Properties) > 0
+	}
+
+	if v.Type[0] != "object" {
+		return false
+	}
+
+	// a free-form object, without any properties, is a hash
+	if v.AdditionalProperties == nil {
+		return len(v.AllOf) > 0 || len(v.Properties) > 0
+	}
+	return v.AdditionalProperties == false
+}
+
+func (p *parser) parseObject(name string, v *base.Schema) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.BaseClass = "T::Struct"
+	t.Serializable = p.opts.Serializers
+	t.Validations = p.opts.Validations
+	t.Mutable = p.opts.Mutable
+	if mutable, ok := v.Extensions[sorbetMutableExtension].(bool); ok {
+		t.Mutable = mutable
+	}
+	if p.opts.ClosedObjects && v.AdditionalProperties == false {
+		t.Closed = true
+		t.Comment = appendComment(t.Comment, "Doesn't allow any properties that aren't declared")
+	}
+
+	if p.interfaceBases[t.TypeName] {
+		p.log.infof("%s is only used as an allOf base, so will be generated as an interface", name)
+		t.BaseClass = ""
+		t.IsInterface = true
+	}
+
+	for _, sp := range v.AllOf {
+		if isSchemaRef(sp) && p.interfaceBases[p.refTypeName(sp.GetReference())] {
+			t.Interfaces = append(t.Interfaces, p.refTypeName(sp.GetReference()))
+		}
+	}
+
+	source := v
+	if p.opts.AllOfStyle == AllOfStyleInherit {
+		if parent, local, ok := p.inheritedBase(name, v); ok {
+			t.BaseClass = parent
+			source = local
+		}
+	}
+
+	properties, required, siblings := p.collectProperties(name, source)
+
+	// iterate in a consistent order, so generated child types are consistent between runs
+	for _, propertyName := range sortedKeys(properties) {
+		v2 := properties[propertyName]
+		prop := Property{
+			Name:       propName(propertyName),
+			SchemaName: propertyName,
+			Type:       SorbetUntyped,
+			Required:   slices.Contains(required, propertyName),
+			Mutable:    t.Mutable,
+		}
+
+		if schema := p.schema(v2); schema != nil {
+			prop.ReadOnly = schema.ReadOnly
+			prop.WriteOnly = schema.WriteOnly
+		}
+		if (prop.ReadOnly && p.opts.Role == RoleRequest) || (prop.WriteOnly && p.opts.Role == RoleResponse) {
+			p.log.debugf("Skipping %s.%s, as it isn't used in a %s", name, propertyName, p.opts.Role)
+			continue
+		}
+
+		if ref, ok := refProperty(v2); ok {
+			prop.Type = p.refTypeName(ref.GetReference())
+
+			if !isSchemaRef(v2) {
+				wrapper := v2.Schema()
+				prop.Comment = prepareComment(wrapper.Description)
+				prop.Deprecated = wrapper.Deprecated != nil && *wrapper.Deprecated
+				prop.Nullable = wrapper.Nullable != nil && *wrapper.Nullable
+				if wrapper.Default != nil {
+					prop.Default = p.refDefault(name, propertyName, ref, prop, wrapper.Default)
+				}
+			} else if s, ok := siblings[propertyName]; ok {
+				prop.Comment = prepareComment(s.Description)
+				prop.Deprecated = s.Deprecated
+				prop.Nullable = s.Nullable
+				if s.HasDefault {
+					prop.Default = p.refDefault(name, propertyName, ref, prop, s.Default)
+				}
+			}
+
+			// a `T::Enum` can't be nilable itself, so a `null` member must instead make the property nilable
+			if schema := p.schema(ref); schema != nil && hasNullEnum(schema) {
+				prop.Nullable = true
+			}
+
+			if prop.Required && p.visiting[prop.Type] {
+				// a required circular reference could never be constructed, so must be allowed to be `nil`
+				p.report(DiagnosticWarning, name, propertyName, "is a circular reference to %s, so will be nilable", prop.Type)
+				prop.Nullable = true
+			}
+		} else {
+			schema := v2.Schema()
+			schemaTypes, nullable := nonNullTypes(schema.Type)
+			prop.Comment = prepareComment(schema.Description)
+			prop.Deprecated = schema.Deprecated != nil && *schema.Deprecated
+
+			if ty, ok := p.extensionType(name, propertyName, schema); ok {
+				prop.Type = ty
+				prop.Nullable = nullable || (schema.Nullable != nil && *schema.Nullable)
+				t.Properties = append(t.Properties, prop)
+				continue
+			}
+
+			if len(schemaTypes) == 0 && (len(schema.OneOf) > 0 || len(schema.AnyOf) > 0) {
+				keyword, members := "oneOf", schema.OneOf
+				if len(members) == 0 {
+					keyword, members = "anyOf", schema.AnyOf
+				}
+				union, nilable, childTypes := p.unionMembers(name+"_"+propertyName, keyword, members)
+				types = append(types, childTypes...)
+				prop.Type = sorbetUnion(union)
+				prop.Nullable = nilable || nullable || (schema.Nullable != nil && *schema.Nullable)
+				t.Properties = append(t.Properties, prop)
+				continue
+			}
+
+			if len(schemaTypes) == 0 && schema.Not != nil {
+				p.report(DiagnosticUntyped, name, propertyName, "is a `not` schema, which Sorbet can't express, so will be treated as %s", SorbetUntyped)
+				prop.Comment = appendComment(prop.Comment, notComment)
+				t.Properties = append(t.Properties, prop)
+				continue
+			}
+
+			if len(schemaTypes) == 0 {
+				ty, ok := p.inferType(name+"."+propertyName, schema)
+				if !ok {
+					p.report(DiagnosticSkipped, name, propertyName, "has no Type, so will be skipped")
+					continue
+				}
+				schemaTypes = []string{ty}
+			}
+
+			prop.Nullable = nullable || (schema.Nullable != nil && *schema.Nullable) || hasNullEnum(schema)
+
+			if len(schemaTypes) > 1 {
+				prop.Type = p.multiType(name, propertyName, schema, schemaTypes)
+				t.Properties = append(t.Properties, prop)
+				continue
+			}
+
+			// a struct can't be defaulted from a literal
+			isStruct := false
+			// an inline enum is generated as its own T::Enum, whose default must be one of its values
+			var enumType *Type
+			// the Ruby literal for an empty array or map, which an optional collection can default to
+			emptyCollection := ""
+
+			switch schemaTypes[0] {
+			case "string":
+				if p.opts.EnumStyle == EnumStyleTEnum && len(schema.Enum) > 0 {
+					_, childTypes, generated := p.parseRef(v2, func() (string, []Type) {
+						childTypes := p.parseString(name+"_"+propertyName, schema)
+						return childTypes[len(childTypes)-1].TypeName, childTypes
+					})
+					if child := childTypes[len(childTypes)-1]; len(child.Enum) > 0 {
+						if generated {
+							types = append(types, childTypes...)
+						}
+						prop.Type = child.TypeName
+						enumType = &child
+						break
+					}
+				}
+
+				prop.Type = p.stringType(schema)
+				if binaryFormats[schema.Format] != "" || schema.Format == "uuid" || schema.Format == "decimal" {
+					prop.Format = schema.Format
+				}
+				prop.Comment = appendComment(prop.Comment, p.decimalComment(schema))
+				prop.Pattern = schema.Pattern
+				prop.MinLength = schema.MinLength
+				prop.MaxLength = schema.MaxLength
+			case "boolean":
+				prop.Type = "T::Boolean"
+			case "integer":
+				prop.Type = p.integerType(name, propertyName, schema)
+				prop.Format = schema.Format
+				prop.Numeric = numericConstraints(schema)
+			case "number":
+				prop.Type = p.numberType(name, propertyName, schema)
+				if schema.Format == "decimal" {
+					prop.Format = schema.Format
+				}
+				prop.Comment = appendComment(prop.Comment, p.decimalComment(schema))
+				prop.Numeric = numericConstraints(schema)
+			case "object":
+				typeName, childTypes, generated := p.parseRef(v2, func() (string, []Type) {
+					return p.parseNestedObject(name+"_"+propertyName, schema)
+				})
+				if generated {
+					types = append(types, childTypes...)
+				}
+
+				prop.Type = typeName
+				child := childTypes[len(childTypes)-1]
+				isStruct = child.IsObject() && !child.IsMap
+				// a map is generated as a class, rather than a hash, when it's a wrapper
+				if child.IsMap && p.opts.AliasStyle != AliasStyleWrapper {
+					emptyCollection = "{}"
+				}
+			case "array":
+				if ty, comment, childTypes, ok := p.tupleType(name+"_"+propertyName, schema); ok {
+					types = append(types, childTypes...)
+					prop.Type = ty
+					prop.Comment = appendComment(prop.Comment, comment)
+					break
+				}
+
+				typeName, childTypes, generated := p.parseRef(v2, func() (string, []Type) {
+					return p.itemsType(name+"_"+propertyName, schema)
+				})
+				if generated {
+					types = append(types, childTypes...)
+				}
+
+				prop.IsArray = true
+				prop.Type = typeName
+				emptyCollection = "[]"
+
+				if schema.Items != nil && schema.Items.IsA() && !isSchemaRef(schema.Items.A) {
+					items := schema.Items.A.Schema()
+					if slices.Contains(items.Type, "integer") || (slices.Contains(items.Type, "string") && binaryFormats[items.Format] != "") {
+						prop.Format = items.Format
+					}
+				}
+			default:
+				p.report(DiagnosticUntyped, name, propertyName, "has an unsupported type %#v, which will be treated as %s", schemaTypes[0], SorbetUntyped)
+			}
+
+			if lit, ok := p.defaultLiteral(name, propertyName, schema, prop.Type); ok {
+				if isStruct && lit != "nil" {
+					p.report(DiagnosticWarning, name, propertyName, "has a default, but is generated as a struct, so it will be ignored")
+				} else if lit == "nil" && prop.Required && !prop.Nullable {
+					p.report(DiagnosticWarning, name, propertyName, "has a `null` default, but isn't nullable, so it will be ignored")
+				} else if enumType != nil && lit != "nil" {
+					if i := slices.IndexFunc(enumType.Enum, func(e Enum) bool { return e.Literal == lit }); i >= 0 {
+						prop.Default = enumType.TypeName + "::" + enumType.Enum[i].Name
+					} else {
+						p.report(DiagnosticWarning, name, propertyName, "has a default (`  %s `) which isn't one of its enum values, so it will be ignored", lit)
+					}
+				} else {
+					prop.Default = lit
+				}
+			}
+
+			if p.opts.CollectionDefaults && emptyCollection != "" && prop.Default == "" && !prop.Required && !prop.Nullable {
+				prop.Default = emptyCollection
+				prop.CollectionDefault = true
+			}
+		}
+
+		t.Properties = append(t.Properties, prop)
+	}
+
+	// ensure that we have consistent output
+	slices.SortStableFunc(t.Properties, func(a, b Property) bool {
+		return a.Name < b.Name
+	})
+
+	for i := range t.Properties {
+		prop := &t.Properties[i]
+		if prop.Numeric != nil {
+			prop.Comment = appendComment(prop.Comment, prop.Numeric.String())
+		}
+		if p.opts.Examples && !isSchemaRef(properties[prop.SchemaName]) {
+			prop.Comment = appendComment(prop.Comment, exampleComment(properties[prop.SchemaName].Schema()))
+		}
+		if p.opts.Role == RoleBoth && prop.ReadOnly {
+			prop.Comment = appendComment(prop.Comment, "Read only, so is only present in responses")
+		}
+		if p.opts.Role == RoleBoth && prop.WriteOnly {
+			prop.Comment = appendComment(prop.Comment, "Write only, so is only present in requests")
+		}
+	}
+
+	if v.AdditionalProperties == true {
+		t.AdditionalProperties = SorbetUntyped
+	} else if v.AdditionalProperties != nil && v.AdditionalProperties != false {
+		sp, ok := v.AdditionalProperties.(*base.SchemaProxy)
+		if ok && isSchemaRef(sp) {
+			t.AdditionalProperties = p.refTypeName(sp.GetReference())
+		} else if ok {
+			schema := sp.Schema()
+
+			if len(schema.Type) > 0 {
+				switch schema.Type[0] {
+				case "string":
+					t.AdditionalProperties = p.stringType(schema)
+				case "boolean":
+					t.AdditionalProperties = "T::Boolean"
+				case "integer":
+					t.AdditionalProperties = p.integerType(name, "", schema)
+				case "number":
+					t.AdditionalProperties = p.numberType(name, "", schema)
+				case "object":
+					typeName, childTypes := p.parseNestedObject(name+"_value", schema)
+					types = append(types, childTypes...)
+
+					t.AdditionalProperties = typeName
+				case "array":
+					typeName, childTypes := p.itemsType(name+"_value", schema)
+					types = append(types, childTypes...)
+
+					t.AdditionalProperties = fmt.Sprintf("T::Array[%s]", typeName)
+				default:
+					p.report(DiagnosticSkipped, name, "", "has additionalProperties of an unsupported type %#v, which will be skipped", schema.Type[0])
+				}
+			} else if len(schema.Properties) == 0 && len(schema.AllOf) == 0 {
+				// a schema without any constraints allows any value
+				t.AdditionalProperties = SorbetUntyped
+			} else {
+				p.report(DiagnosticSkipped, name, "", "has additionalProperties without a type, which will be skipped")
+			}
+		} else {
+			// an empty schema, i.e. `additionalProperties: {}`, isn't built into a SchemaProxy, but allows any value
+			t.AdditionalProperties = SorbetUntyped
+		}
+	}
+
+	// an object without any properties, or constraints on its additional properties, is free-form, so allows any
+	// properties
+	if len(properties) == 0 && v.AdditionalProperties == nil && t.BaseClass == "T::Struct" && len(t.Interfaces) == 0 {
+		t.AdditionalProperties = SorbetUntyped
+	}
+
+	var constraints []string
+	if v.MinProperties != nil {
+		constraints = append(constraints, fmt.Sprintf("minProperties: %d", *v.MinProperties))
+	}
+	if v.MaxProperties != nil {
+		constraints = append(constraints, fmt.Sprintf("maxProperties: %d", *v.MaxProperties))
+	}
+	t.Comment = appendComment(t.Comment, strings.Join(constraints, ", "))
+
+	if t.AdditionalProperties != "" {
+		t.MapKeyType = "T.any(Symbol, String)"
+		if p.opts.TypedMaps {
+			t.MapKeyType = "String"
+		}
+		// declared properties can only be kept by a struct, with the additional properties alongside them
+		t.IsMap = len(t.Properties) == 0
+	}
+
+	types = append(types, t)
+
+	return types
+}
+
+func (p *parser) parseArray(name string, v *base.Schema) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+
+	if ty, comment, childTypes, ok := p.tupleType(name, v); ok {
+		types = append(types, childTypes...)
+		t.Alias = ty
+		t.Comment = appendComment(t.Comment, comment)
+		types = append(types, t)
+		return
+	}
+
+	typeName, childTypes := p.itemsType(name, v)
+	types = append(types, childTypes...)
+
+	t.Alias = typeName
+	t.IsArray = true
+	// a nullable array, rather than nullable items, which are instead `T.nilable` within the array
+	t.Nilable = v.Nullable != nil && *v.Nullable
+
+	types = append(types, t)
+
+	return
+}
+
+// itemsType returns the Sorbet type for the `items` of an array schema, recursing into nested arrays so i.e. an array
+// of arrays of strings has items of `T::Array[String]`. Inline objects are generated as a child type named after the
+// array, with an `Item` suffix
+func (p *parser) itemsType(name string, v *base.Schema) (string, []Type) {
+	if v.Items == nil {
+		p.report(DiagnosticUntyped, name, "", "is an array without items, so its items will be treated as %s", SorbetUntyped)
+		return SorbetUntyped, nil
+	}
+
+	// IsB here is whether this is an `items: true`
+	if v.Items.IsB() {
+		return SorbetUntyped, nil
+	}
+
+	return p.elementType(name, p.itemName(name), v.Items.A)
+}
+
+// itemName returns the name of the child type for the inline items of an array schema. This is the array's name with
+// an `Item` suffix, unless Inflect is enabled, in which case the array's name is singularized, i.e. `Pet_tags` will be
+// `Pet_tag`, as long as that doesn't clash with a component schema, and isn't the same as the array's name
+func (p *parser) itemName(name string) string {
+	if !p.opts.Inflect {
+		return name + "_item"
+	}
+
+	i := strings.LastIndexAny(name, "_.") + 1
+	singular := name[:i] + singularize(name[i:])
+	if singular == name || p.componentTypes[p.typeName(singular)] {
+		return name + "_item"
+	}
+	return singular
+}
+
+// elementType returns the Sorbet type for a member of an array schema, such as its `items` or one of its
+// `prefixItems`. Inline objects are generated as a child type named childName. A nullable member is `T.nilable`, i.e.
+// an array of nullable strings is `T::Array[T.nilable(String)]`
+func (p *parser) elementType(name string, childName string, s *base.SchemaProxy) (string, []Type) {
+	if isSchemaRef(s) {
+		return p.refTypeName(s.GetReference()), nil
+	}
+
+	schema := s.Schema()
+	ty, types, generated := p.parseRef(s, func() (string, []Type) {
+		return p.nonNullElementType(name, childName, schema)
+	})
+	if !generated {
+		types = nil
+	}
+
+	_, nullable := nonNullTypes(schema.Type)
+	if (nullable || (schema.Nullable != nil && *schema.Nullable)) && ty != SorbetUntyped {
+		ty = fmt.Sprintf("T.nilable(%s)", ty)
+	}
+
+	return ty, types
+}
+
+// nonNullElementType returns the Sorbet type for a member of an array schema which isn't a `$ref` to a generated type,
+// ignoring whether it's nullable
+func (p *parser) nonNullElementType(name string, childName string, schema *base.Schema) (string, []Type) {
+	itemTypes, _ := nonNullTypes(schema.Type)
+	if len(itemTypes) > 1 {
+		return p.multiType(name, "", schema, itemTypes), nil
+	}
+
+	itemType := ""
+	if len(itemTypes) > 0 {
+		itemType = itemTypes[0]
+	} else if ty, ok := p.inferType(childName, schema); ok {
+		itemType = ty
+	} else {
+		p.report(DiagnosticUntyped, name, "", "has items without a type, which will be treated as %s", SorbetUntyped)
+		return SorbetUntyped, nil
+	}
+
+	if ty, ok := p.scalarType(name, "", schema, itemType); ok {
+		return ty, nil
+	}
+
+	switch itemType {
+	case "object":
+		return p.parseNestedObject(childName, schema)
+	case "array":
+		typeName, childTypes := p.itemsType(childName, schema)
+		return fmt.Sprintf("T::Array[%s]", typeName), childTypes
+	default:
+		p.report(DiagnosticUntyped, name, "", "has items of an unsupported type %#v, which will be treated as %s", itemType, SorbetUntyped)
+	}
+
+	return SorbetUntyped, nil
+}
+
+// tupleType returns the Sorbet type for an array schema with `prefixItems`, which is a fixed-arity tuple such as
+// `[String, Integer]`. Sorbet can't express a tuple followed by further `items`, so in that case the array is typed as
+// `T::Array` of any of its members, alongside a comment explaining why. Inline objects are generated as child types
+// with an `Item` suffix and their position, i.e. `Item1`
+func (p *parser) tupleType(name string, v *base.Schema) (ty string, comment string, types []Type, ok bool) {
+	if len(v.PrefixItems) == 0 {
+		return "", "", nil, false
+	}
+
+	var members []string
+	for i, s := range v.PrefixItems {
+		memberType, childTypes := p.elementType(name, fmt.Sprintf("%s_item_%d", name, i+1), s)
+		types = append(types, childTypes...)
+		members = append(members, memberType)
+	}
+
+	// `items: false` closes the tuple, the same as not having `items` at all
+	if v.Items == nil || (v.Items.IsB() && !v.Items.B) {
+		return fmt.Sprintf("[%s]", strings.Join(members, ", ")), "", types, true
+	}
+
+	itemsType, childTypes := p.itemsType(name, v)
+	types = append(types, childTypes...)
+
+	var union []string
+	for _, member := range append(members, itemsType) {
+		if !slices.Contains(union, member) {
+			union = append(union, member)
+		}
+	}
+
+	comment = fmt.Sprintf("A tuple of `[%s]`, followed by any number of `%s`, which Sorbet can't express as a fixed-arity type", strings.Join(members, ", "), itemsType)
+	return fmt.Sprintf("T::Array[%s]", sorbetUnion(union)), comment, types, true
+}
+
+// parseNestedObject parses an inline object schema into its own child type, returning the child's type name alongside
+// the parsed types
+func (p *parser) parseNestedObject(name string, v *base.Schema) (string, []Type) {
+	return p.typeName(name), p.parseObject(name, v)
+}
+
+// refTypeName returns the Sorbet type name for a `$ref`, i.e. `#/components/schemas/pet` will be `Pet`
+func (p *parser) refTypeName(ref string) string {
+	return p.typeName(refSchemaName(ref))
+}
+
+// sorbetUnion returns the Sorbet type for a union of the given member types
+func sorbetUnion(members []string) string {
+	if len(members) == 1 {
+		return members[0]
+	}
+	return fmt.Sprintf("T.any(%s)", strings.Join(members, ", "))
+}
+
+// refProperty returns the `$ref` that a property refers to, either directly, or as the only member of an `allOf`, which
+// is commonly used to describe a property alongside a `$ref`. The `$ref` is used as is, rather than inlining the schema
+// it refers to, so a self-reference doesn't recurse
+func refProperty(sp *base.SchemaProxy) (*base.SchemaProxy, bool) {
+	if isSchemaRef(sp) {
+		return sp, true
+	}
+
+	v := sp.Schema()
+	if v == nil || len(v.Type) > 0 || len(v.Properties) > 0 || len(v.AllOf) != 1 || !isSchemaRef(v.AllOf[0]) {
+		return nil, false
+	}
+	return v.AllOf[0], true
+}
+
+// constValue returns the value of the schema's `const`, if set. libopenapi doesn't yet model `const`, so it's read from
+// the schema's underlying YAML node
+func constValue(v *base.Schema) (any, bool) {
+	low := v.GoLow()
+	if low == nil || low.ParentProxy == nil {
+		return nil, false
+	}
+
+	node := low.ParentProxy.GetValueNode()
+	if node == nil {
+		return nil, false
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "const" {
+			continue
+		}
+
+		var value any
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return nil, false
+		}
+		return value, true
+	}
+
+	return nil, false
+}
+
+// hasNullEnum reports whether the schema's `enum` contains `null`
+func hasNullEnum(v *base.Schema) bool {
+	return slices.IndexFunc(v.Enum, func(e any) bool { return e == nil }) >= 0
+}
+
+// isNullSchema reports whether the schema only allows `null`, i.e. `{"type": "null"}` or a bare `{"nullable": true}`
+func isNullSchema(v *base.Schema) bool {
+	if len(v.Type) == 0 {
+		return v.Nullable != nil && *v.Nullable
+	}
+	return len(v.Type) == 1 && v.Type[0] == "null"
+}
+
+// parseDiscriminated parses a `oneOf` with a `discriminator` into a module that each of its members will include, or
+// returns false if the members can't be determined
+func (p *parser) parseDiscriminated(name string, v *base.Schema) (types []Type, ok bool) {
+	if v.Discriminator.PropertyName == "" {
+		p.report(DiagnosticWarning, name, "", "has a discriminator without a propertyName, so will be generated as a union")
+		return nil, false
+	}
+
+	mapping := v.Discriminator.Mapping
+	if len(mapping) == 0 {
+		// without an explicit mapping, the discriminator's values are the names of the referenced schemas
+		mapping = make(map[string]string)
+		for _, member := range v.OneOf {
+			if !isSchemaRef(member) {
+				p.report(DiagnosticWarning, name, "", "has a discriminator, but an inline member, so will be generated as a union")
+				return nil, false
+			}
+			parts := strings.Split(member.GetReference(), "/")
+			mapping[parts[len(parts)-1]] = member.GetReference()
+		}
+	}
+
+	d := Discriminator{
+		PropertyName: v.Discriminator.PropertyName,
+		Property:     propName(v.Discriminator.PropertyName),
+	}
+	for _, value := range sortedKeys(mapping) {
+		d.Mapping = append(d.Mapping, DiscriminatorMapping{
+			Value:    value,
+			Literal:  rubyString(value),
+			TypeName: p.refTypeName(mapping[value]),
+		})
+	}
+
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.Discriminator = &d
+	// `sealed!` requires that all members are defined in the same file
+	t.Sealed = p.opts.SingleFile != ""
+
+	types = append(types, t)
+	return types, true
+}
+
+// applyDiscriminators makes each member of a discriminated Type include its module, declaring the discriminator
+// property on the member if it isn't already
+func (p *parser) applyDiscriminators(types []Type) {
+	byName := make(map[string]*Type, len(types))
+	for i := range types {
+		byName[types[i].TypeName] = &types[i]
+	}
+
+	for _, t := range types {
+		if t.Discriminator == nil {
+			continue
+		}
+
+		for _, m := range t.Discriminator.Mapping {
+			member, ok := byName[m.TypeName]
+			if ok && member.IsMap && len(member.Properties) == 0 {
+				// a free-form object must instead be an empty struct, so it can include the module
+				member.IsMap = false
+				member.AdditionalProperties = ""
+			}
+			if !ok || !member.IsObject() {
+				p.report(DiagnosticWarning, t.SchemaName, "", "has a discriminator mapping %#v to %s, which isn't a struct, so can't include %s", m.Value, m.TypeName, t.TypeName)
+				continue
+			}
+
+			if slices.Contains(member.Interfaces, t.TypeName) {
+				continue
+			}
+			member.Interfaces = append(member.Interfaces, t.TypeName)
+
+			if slices.IndexFunc(member.Properties, func(prop Property) bool { return prop.SchemaName == t.Discriminator.PropertyName }) >= 0 {
+				continue
+			}
+
+			member.Properties = append(member.Properties, Property{
+				Name:       t.Discriminator.Property,
+				SchemaName: t.Discriminator.PropertyName,
+				Type:       "String",
+				Required:   true,
+				Default:    m.Literal,
+				Mutable:    member.Mutable,
+			})
+			slices.SortStableFunc(member.Properties, func(a, b Property) bool {
+				return a.Name < b.Name
+			})
+		}
+	}
+}
+
+// parseUnion parses the members of a `oneOf` or `anyOf` into a `T.any(...)` alias, collapsing a `null` member into a
+// `T.nilable(...)`
+func (p *parser) parseUnion(name string, v *base.Schema, keyword string, members []*base.SchemaProxy) (types []Type) {
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = prepareComment(v.Description)
+	t.Union, t.Nilable, types = p.unionMembers(name, keyword, members)
+	t.Alias = sorbetUnion(t.Union)
+
+	types = append(types, t)
+	return
+}
+
+// unionMembers parses the members of a `oneOf` or `anyOf`, returning the Sorbet type of each member, whether a `null`
+// member makes the union nilable, and the types generated for any inline members
+func (p *parser) unionMembers(name string, keyword string, members []*base.SchemaProxy) (union []string, nilable bool, types []Type) {
+	for i, sp := range members {
+		if isSchemaRef(sp) {
+			union = append(union, p.refTypeName(sp.GetReference()))
+			continue
+		}
+
+		schema := sp.Schema()
+		if isNullSchema(schema) {
+			nilable = true
+			continue
+		}
+
+		memberName := fmt.Sprintf("%s_option_%d", name, i+1)
+		typeName, childTypes, generated := p.parseRef(sp, func() (string, []Type) {
+			return p.typeName(memberName), p.parseSchema(memberName, schema)
+		})
+		if len(childTypes) == 0 {
+			p.report(DiagnosticUntyped, name, "", "has an unparseable %s member %d, which will be treated as %s", keyword, i+1, SorbetUntyped)
+			union = append(union, SorbetUntyped)
+			continue
+		}
+		if generated {
+			types = append(types, childTypes...)
+		}
+
+		union = append(union, typeName)
+	}
+
+	if len(union) == 0 {
+		p.report(DiagnosticUntyped, name, "", "only has `null` members in its %s, which will be treated as %s", keyword, SorbetUntyped)
+		union = append(union, SorbetUntyped)
+		nilable = false
+	}
+
+	return union, nilable, types
+}
+
+// inferType infers the type of a schema without a `type` from its other keywords, such as `properties` implying an
+// object, or returns false if it can't be inferred
+func (p *parser) inferType(name string, v *base.Schema) (string, bool) {
+	// the first non-`null` enum value determines the type, as `null` only makes it nilable
+	firstEnum := slices.IndexFunc(v.Enum, func(e any) bool { return e != nil })
+
+	var ty string
+	switch {
+	case len(v.Properties) > 0:
+		ty = "object"
+	case v.Items != nil:
+		ty = "array"
+	case firstEnum >= 0:
+		switch v.Enum[firstEnum].(type) {
+		case string:
+			ty = "string"
+		case bool:
+			ty = "boolean"
+		case int, int64:
+			ty = "integer"
+		case float64:
+			ty = "number"
+		default:
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	p.log.debugf("%s has no Type, so has been inferred as %s", name, ty)
+	return ty, true
+}
+
+// nonNullTypes returns the schema's types, excluding `null`, which instead indicates that the schema is nullable, as
+// with OpenAPI 3.1's `type: [string, "null"]`
+func nonNullTypes(schemaTypes []string) (types []string, nullable bool) {
+	for _, ty := range schemaTypes {
+		if ty == "null" {
+			nullable = true
+			continue
+		}
+		types = append(types, ty)
+	}
+	return
+}
+
+// scalarType returns the Sorbet type for a scalar schema type, or false if the type isn't a scalar
+func (p *parser) scalarType(name string, property string, v *base.Schema, ty string) (string, bool) {
+	switch ty {
+	case "string":
+		return p.stringType(v), true
+	case "boolean":
+		return "T::Boolean", true
+	case "integer":
+		return p.integerType(name, property, v), true
+	case "number":
+		return p.numberType(name, property, v), true
+	}
+	return "", false
+}
+
+// multiType returns the Sorbet type for a schema with multiple types, such as `type: [string, integer]`
+func (p *parser) multiType(name string, property string, v *base.Schema, schemaTypes []string) string {
+	var members []string
+	for _, ty := range schemaTypes {
+		member, ok := p.scalarType(name, property, v, ty)
+		if !ok {
+			p.report(DiagnosticUntyped, name, property, "has an unsupported type %#v in a multi-type schema, which will be treated as %s", ty, SorbetUntyped)
+			return SorbetUntyped
+		}
+		members = append(members, member)
+	}
+	return sorbetUnion(members)
+}
+
+func (p *parser) parseSchema(name string, v *base.Schema) (types []Type) {
+	defer func() {
+		// the top-level type is always the last to be parsed
+		if v.Deprecated != nil && *v.Deprecated && len(types) > 0 {
+			types[len(types)-1].Deprecated = true
+		}
+		if p.opts.Examples && len(types) > 0 {
+			types[len(types)-1].Comment = appendComment(types[len(types)-1].Comment, exampleComment(v))
+		}
+	}()
+
+	if ty, ok := p.extensionType(name, "", v); ok {
+		_, nullable := nonNullTypes(v.Type)
+
+		t := Type{}
+		t.SchemaName = name
+		t.TypeName = p.typeName(name)
+		t.Filename = p.fileName(name)
+		t.Comment = prepareComment(v.Description)
+		t.Alias = ty
+		t.Nilable = nullable || (v.Nullable != nil && *v.Nullable)
+
+		types = append(types, t)
+		return
+	}
+
+	if value, ok := constValue(v); ok {
+		// a `const` only allows a single value, which is the same as an `enum` of that value
+		withEnum := *v
+		withEnum.Enum = []any{value}
+		v = &withEnum
+	}
+
+	if len(v.OneOf) > 0 {
+		if v.Discriminator != nil {
+			if types, ok := p.parseDiscriminated(name, v); ok {
+				return types
+			}
+		}
+		return p.parseUnion(name, v, "oneOf", v.OneOf)
+	}
+
+	if len(v.AnyOf) > 0 {
+		return p.parseUnion(name, v, "anyOf", v.AnyOf)
+	}
+
+	if len(v.Type) == 0 && len(v.AllOf) > 0 {
+		return p.parseObject(name, v)
+	}
+
+	schemaTypes, nullable := nonNullTypes(v.Type)
+	if len(schemaTypes) == 0 && v.Not != nil {
+		p.report(DiagnosticUntyped, name, "", "is a `not` schema, which Sorbet can't express, so will be treated as %s", SorbetUntyped)
+		t := Type{}
+		t.SchemaName = name
+		t.TypeName = p.typeName(name)
+		t.Filename = p.fileName(name)
+		t.Comment = appendComment(prepareComment(v.Description), notComment)
+		t.Alias = SorbetUntyped
+
+		types = append(types, t)
+		return
+	}
+
+	if len(schemaTypes) == 0 {
+		ty, ok := p.inferType(name, v)
+		if !ok {
+			p.report(DiagnosticSkipped, name, "", "has no Type, so will be skipped")
+			return
+		}
+		schemaTypes = []string{ty}
+	}
+
+	if len(schemaTypes) > 1 {
+		t := Type{}
+		t.SchemaName = name
+		t.TypeName = p.typeName(name)
+		t.Filename = p.fileName(name)
+		t.Comment = prepareComment(v.Description)
+		t.Alias = p.multiType(name, "", v, schemaTypes)
+		t.Nilable = nullable
+
+		types = append(types, t)
+		return
+	}
+
+	switch schemaTypes[0] {
+	case "string":
+		types = append(types, p.parseString(name, v)...)
+	case "boolean":
+		types = append(types, p.parseBoolean(name, v)...)
+	case "integer":
+		types = append(types, p.parseInteger(name, v)...)
+	case "number":
+		types = append(types, p.parseNumber(name, v)...)
+	case "object":
+		types = append(types, p.parseObject(name, v)...)
+	case "array":
+		types = append(types, p.parseArray(name, v)...)
+	default:
+		p.report(DiagnosticSkipped, name, "", "has an unsupported type %#v, so will be skipped", v.Type)
+	}
+
+	if nullable && len(types) > 0 {
+		// the top-level type is always the last to be parsed
+		t := &types[len(types)-1]
+		if t.IsObject() || t.IsEnum() || t.IsInterface {
+			p.report(DiagnosticWarning, name, "", "is nullable, but this can't be expressed for a class, so it will be ignored")
+		} else {
+			t.Nilable = true
+		}
+	}
+
+	return
+}
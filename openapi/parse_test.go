@@ -0,0 +1,2154 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		file   string
+		want   string
+	}{
+		{
+			name:   "schema",
+			schema: "{type: number}",
+			file:   "price.rb",
+			want:   "Price = T.type_alias { Float}",
+		},
+		{
+			name:   "property",
+			schema: "{type: object, properties: {amount: {type: number, format: double}}}",
+			file:   "price.rb",
+			want:   "const :amount, T.nilable(Float)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas("\n    Price: "+tt.schema+"\n"), Options{})
+
+			assertContains(t, files, tt.file, tt.want)
+		})
+	}
+}
+
+func TestIntegerFormats(t *testing.T) {
+	schema := `
+    Counter:
+      type: object
+      properties:
+        small: {type: integer, format: int32}
+        large: {type: integer, format: int64}
+        plain: {type: integer}
+`
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"const :small, T.nilable(Integer) # format: int32",
+				"const :large, T.nilable(Integer) # format: int64",
+				"const :plain, T.nilable(Integer)\n",
+			},
+		},
+		{
+			name: "int64 type",
+			opts: Options{Int64Type: "BigInteger"},
+			want: []string{
+				"const :small, T.nilable(Integer) # format: int32",
+				"const :large, T.nilable(BigInteger) # format: int64",
+				"const :plain, T.nilable(Integer)\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(schema), tt.opts)
+
+			assertContains(t, files, "counter.rb", tt.want...)
+			if strings.Contains(files["counter.rb"], "require_relative './big_integer'") {
+				t.Errorf("counter.rb requires the int64 type, which isn't generated:\n%s", files["counter.rb"])
+			}
+		})
+	}
+}
+
+func TestEnumStyle(t *testing.T) {
+	schema := `
+    Status:
+      type: string
+      enum: [active, in-active, in_active]
+`
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "tenum",
+			want: []string{
+				"class Status < T::Enum",
+				"Active = new('active')",
+				"InActive = new('in-active')",
+				"InActive2 = new('in_active')",
+			},
+		},
+		{
+			name: "alias",
+			opts: Options{EnumStyle: EnumStyleAlias},
+			want: []string{"Status = T.type_alias { String}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(schema), tt.opts)
+
+			assertContains(t, files, "status.rb", tt.want...)
+		})
+	}
+}
+
+func TestNonStringEnums(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		want     []string
+		warnings []string
+	}{
+		{
+			name:   "integer",
+			schema: "{type: integer, enum: [1, 2]}",
+			want:   []string{"class Code < T::Enum", "Value1 = new(1)", "Value2 = new(2)"},
+		},
+		{
+			name:   "number",
+			schema: "{type: number, enum: [1, 2.5]}",
+			want:   []string{"Value10 = new(1.0)", "Value25 = new(2.5)"},
+		},
+		{
+			name:   "boolean",
+			schema: "{type: boolean, enum: [true]}",
+			want:   []string{"True = new(true)"},
+		},
+		{
+			name:     "string value for an integer",
+			schema:   "{type: integer, enum: [1, two]}",
+			want:     []string{"Value1 = new(1)"},
+			warnings: []string{"Code has a string enum value (`  two `) for a non-string type, which will be skipped"},
+		},
+		{
+			name:     "integer value for a string",
+			schema:   "{type: string, enum: [1, two]}",
+			want:     []string{"Two = new('two')"},
+			warnings: []string{"Code has a non-string enum type (`  int64 `), which failed to have its type converted to a string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, logs := generateSpecLogs(t, specWithSchemas("\n    Code: "+tt.schema+"\n"), Options{})
+
+			assertContains(t, files, "code.rb", tt.want...)
+			if got := strings.Count(logs, "WARN: Code"); got != len(tt.warnings) {
+				t.Errorf("logged %d warnings, want %d:\n%s", got, len(tt.warnings), logs)
+			}
+			for _, w := range tt.warnings {
+				if !strings.Contains(logs, w) {
+					t.Errorf("didn't log %q:\n%s", w, logs)
+				}
+			}
+		})
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   map[string][]string
+	}{
+		{
+			name: "refs",
+			schema: `
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+`,
+			want: map[string][]string{
+				"pet.rb": {"Pet = T.type_alias { T.any(Cat, Dog)}", "require_relative './cat'", "require_relative './dog'"},
+			},
+		},
+		{
+			name: "inline object",
+			schema: `
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - type: object
+          properties:
+            name: {type: string}
+`,
+			want: map[string][]string{
+				"pet.rb":          {"Pet = T.type_alias { T.any(Cat, PetOption2)}", "require_relative './pet_option_2'"},
+				"pet_option_2.rb": {"class PetOption2", "const :name, T.nilable(String)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(tt.schema+`
+    Cat: {type: object, properties: {meows: {type: boolean}}}
+    Dog: {type: object, properties: {barks: {type: boolean}}}
+`), Options{})
+
+			for file, want := range tt.want {
+				assertContains(t, files, file, want...)
+			}
+		})
+	}
+}
+
+func TestAnyOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "refs",
+			schema: "{anyOf: [{$ref: '#/components/schemas/Cat'}, {$ref: '#/components/schemas/Dog'}]}",
+			want:   "Pet = T.type_alias { T.any(Cat, Dog)}",
+		},
+		{
+			name:   "null member",
+			schema: "{anyOf: [{$ref: '#/components/schemas/Cat'}, {type: 'null'}]}",
+			want:   "Pet = T.type_alias { T.nilable(Cat)}",
+		},
+		{
+			name:   "nullable member",
+			schema: "{anyOf: [{$ref: '#/components/schemas/Cat'}, {$ref: '#/components/schemas/Dog'}, {nullable: true}]}",
+			want:   "Pet = T.type_alias { T.nilable(T.any(Cat, Dog))}",
+		},
+		{
+			name:   "only null",
+			schema: "{anyOf: [{type: 'null'}]}",
+			want:   "Pet = T.type_alias { T.untyped}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(`
+    Pet: `+tt.schema+`
+    Cat: {type: object, properties: {meows: {type: boolean}}}
+    Dog: {type: object, properties: {barks: {type: boolean}}}
+`), Options{})
+
+			assertContains(t, files, "pet.rb", tt.want)
+		})
+	}
+}
+
+func TestAllOf(t *testing.T) {
+	schema := `
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          required: [name]
+          properties:
+            name: {type: string}
+            tag: {type: integer}
+        - type: object
+          properties:
+            tag: {type: string}
+    Base:
+      type: object
+      required: [id]
+      properties:
+        id: {type: integer}
+`
+
+	files, logs := generateSpecLogs(t, specWithSchemas(schema), Options{})
+
+	assertContains(t, files, "pet.rb",
+		"class Pet",
+		"const :id, Integer",
+		"const :name, String",
+		// the last definition of a property wins
+		"const :tag, T.nilable(String)",
+	)
+	if want := "Pet has multiple definitions of property tag through allOf"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+}
+
+func TestAllOfStyleInherit(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		want    []string
+		exclude string
+	}{
+		{
+			name:    "object base",
+			base:    "{type: object, properties: {id: {type: integer}}}",
+			want:    []string{"class Pet < Base\n", "require_relative './base'", "const :name, T.nilable(String)"},
+			exclude: "const :id",
+		},
+		{
+			name: "non-object base",
+			base: "{type: string}",
+			want: []string{"class Pet < T::Struct\n", "const :name, T.nilable(String)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(`
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          properties:
+            name: {type: string}
+    Base: `+tt.base+`
+`), Options{AllOfStyle: AllOfStyleInherit})
+
+			assertContains(t, files, "pet.rb", tt.want...)
+			if tt.exclude != "" && strings.Contains(files["pet.rb"], tt.exclude) {
+				t.Errorf("pet.rb contains the inherited %q:\n%s", tt.exclude, files["pet.rb"])
+			}
+		})
+	}
+}
+
+func TestNullable(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "required and nullable property",
+			schema: "{type: object, required: [name], properties: {name: {type: string, nullable: true}}}",
+			want:   "const :name, T.nilable(String)",
+		},
+		{
+			name:   "required property",
+			schema: "{type: object, required: [name], properties: {name: {type: string}}}",
+			want:   "const :name, String",
+		},
+		{
+			name:   "optional property",
+			schema: "{type: object, properties: {name: {type: string, nullable: false}}}",
+			want:   "const :name, T.nilable(String)",
+		},
+		{
+			name:   "nullable string schema",
+			schema: "{type: string, nullable: true}",
+			want:   "Value = T.type_alias { T.nilable(String)}",
+		},
+		{
+			name:   "nullable integer schema",
+			schema: "{type: integer, nullable: true}",
+			want:   "Value = T.type_alias { T.nilable(Integer)}",
+		},
+		{
+			name:   "nullable number schema",
+			schema: "{type: number, nullable: true}",
+			want:   "Value = T.type_alias { T.nilable(Float)}",
+		},
+		{
+			name:   "nullable boolean schema",
+			schema: "{type: boolean, nullable: true}",
+			want:   "Value = T.type_alias { T.nilable(T::Boolean)}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas("\n    Value: "+tt.schema+"\n"), Options{})
+
+			assertContains(t, files, "value.rb", tt.want)
+		})
+	}
+}
+
+func TestTypeArrays(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "nullable schema",
+			schema: `{type: [string, "null"]}`,
+			want:   "Value = T.type_alias { T.nilable(String)}",
+		},
+		{
+			name:   "nullable and nullable schema",
+			schema: `{type: [string, "null"], nullable: true}`,
+			want:   "Value = T.type_alias { T.nilable(String)}",
+		},
+		{
+			name:   "multiple types",
+			schema: `{type: [string, integer]}`,
+			want:   "Value = T.type_alias { T.any(String, Integer)}",
+		},
+		{
+			name:   "multiple nullable types",
+			schema: `{type: [string, integer, "null"]}`,
+			want:   "Value = T.type_alias { T.nilable(T.any(String, Integer))}",
+		},
+		{
+			name:   "required nullable property",
+			schema: `{type: object, required: [name], properties: {name: {type: [string, "null"]}}}`,
+			want:   "const :name, T.nilable(String)",
+		},
+		{
+			name:   "multiple types property",
+			schema: `{type: object, required: [id], properties: {id: {type: [string, integer]}}}`,
+			want:   "const :id, T.any(String, Integer)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, `
+openapi: 3.1.0
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  schemas:
+    Value: `+tt.schema+`
+`, Options{})
+
+			assertContains(t, files, "value.rb", tt.want)
+		})
+	}
+}
+
+func TestTypedMaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		opts   Options
+		want   []string
+	}{
+		{
+			name:   "map",
+			schema: "{type: object, additionalProperties: {type: integer}}",
+			want:   []string{"Value = T.type_alias { T::Hash[T.any(Symbol, String), Integer] }"},
+		},
+		{
+			name:   "typed map",
+			schema: "{type: object, additionalProperties: {type: boolean}}",
+			opts:   Options{TypedMaps: true},
+			want:   []string{"Value = T.type_alias { T::Hash[String, T::Boolean] }"},
+		},
+		{
+			name:   "typed map of a $ref",
+			schema: "{type: object, additionalProperties: {$ref: '#/components/schemas/Item'}}",
+			opts:   Options{TypedMaps: true},
+			want:   []string{"Value = T.type_alias { T::Hash[String, Item] }", "require_relative './item'"},
+		},
+		{
+			name:   "typed map with properties",
+			schema: "{type: object, properties: {name: {type: string}}, additionalProperties: {type: number}}",
+			opts:   Options{TypedMaps: true},
+			want: []string{
+				"class Value",
+				"const :name, T.nilable(String)",
+				"const :additional_properties, T::Hash[String, Float], default: {}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(`
+    Value: `+tt.schema+`
+    Item: {type: object, properties: {id: {type: integer}}}
+`), tt.opts)
+
+			assertContains(t, files, "value.rb", tt.want...)
+		})
+	}
+}
+
+func TestAdditionalPropertiesValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   map[string][]string
+	}{
+		{
+			name:   "$ref",
+			schema: "{type: object, additionalProperties: {$ref: '#/components/schemas/Widget'}}",
+			want:   map[string][]string{"value.rb": {"T::Hash[T.any(Symbol, String), Widget]", "require_relative './widget'"}},
+		},
+		{
+			name:   "number",
+			schema: "{type: object, additionalProperties: {type: number}}",
+			want:   map[string][]string{"value.rb": {"T::Hash[T.any(Symbol, String), Float]"}},
+		},
+		{
+			name:   "empty schema",
+			schema: "{type: object, additionalProperties: {}}",
+			want:   map[string][]string{"value.rb": {"T::Hash[T.any(Symbol, String), T.untyped]"}},
+		},
+		{
+			name:   "true",
+			schema: "{type: object, additionalProperties: true}",
+			want:   map[string][]string{"value.rb": {"T::Hash[T.any(Symbol, String), T.untyped]"}},
+		},
+		{
+			name:   "inline object",
+			schema: "{type: object, additionalProperties: {type: object, properties: {id: {type: integer}}}}",
+			want: map[string][]string{
+				"value.rb":       {"T::Hash[T.any(Symbol, String), ValueValue]", "require_relative './value_value'"},
+				"value_value.rb": {"class ValueValue", "const :id, T.nilable(Integer)"},
+			},
+		},
+		{
+			name:   "false",
+			schema: "{type: object, properties: {id: {type: integer}}, additionalProperties: false}",
+			want:   map[string][]string{"value.rb": {"class Value", "const :id, T.nilable(Integer)"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(`
+    Value: `+tt.schema+`
+    Widget: {type: object, properties: {id: {type: integer}}}
+`), Options{})
+
+			for file, want := range tt.want {
+				assertContains(t, files, file, want...)
+			}
+		})
+	}
+}
+
+func TestClosedObjects(t *testing.T) {
+	spec := specWithSchemas(`
+    Closed: {type: object, properties: {id: {type: integer}}, additionalProperties: false}
+    Open: {type: object, properties: {id: {type: integer}}, additionalProperties: true}
+    Typed: {type: object, properties: {id: {type: integer}}, additionalProperties: {type: string}}
+`)
+
+	for _, format := range []string{FormatRB, FormatRBI, FormatRBS} {
+		t.Run(format, func(t *testing.T) {
+			files := generateSpec(t, spec, Options{ClosedObjects: true, Format: format})
+			assertContains(t, files, "closed."+format, "Doesn't allow any properties that aren't declared", "def self.closed?")
+
+			for _, file := range []string{"open." + format, "typed." + format} {
+				if strings.Contains(files[file], "closed?") {
+					t.Errorf("%s is closed, but allows additionalProperties:\n%s", file, files[file])
+				}
+			}
+			assertContains(t, files, "typed."+format, "additional_properties")
+		})
+	}
+
+	t.Run("deserializing", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{ClosedObjects: true})
+		assertContains(t, files, "closed.rb", "  sig { returns(T::Boolean) }\n  def self.closed?\n    true\n  end\n")
+		assertContains(t, files, "hash_deserializable.rb", "raise ArgumentError, \"Unknown properties for #{name}")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		if strings.Contains(files["closed.rb"], "closed?") {
+			t.Errorf("closed.rb is closed, but ClosedObjects isn't enabled:\n%s", files["closed.rb"])
+		}
+	})
+}
+
+func TestNestedArrays(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "array",
+			schema: "{type: array, items: {type: string}}",
+			want:   "Value = T.type_alias { T::Array[String]}",
+		},
+		{
+			name:   "two levels",
+			schema: "{type: array, items: {type: array, items: {type: string}}}",
+			want:   "Value = T.type_alias { T::Array[T::Array[String]]}",
+		},
+		{
+			name:   "three levels",
+			schema: "{type: array, items: {type: array, items: {type: array, items: {type: integer}}}}",
+			want:   "Value = T.type_alias { T::Array[T::Array[T::Array[Integer]]]}",
+		},
+		{
+			name:   "two levels of $refs property",
+			schema: "{type: object, properties: {grid: {type: array, items: {type: array, items: {$ref: '#/components/schemas/Cell'}}}}}",
+			want:   "const :grid, T.nilable(T::Array[T::Array[Cell]])",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(`
+    Value: `+tt.schema+`
+    Cell: {type: object, properties: {id: {type: integer}}}
+`), Options{})
+
+			assertContains(t, files, "value.rb", tt.want)
+		})
+	}
+}
+
+func TestInlineObjectItems(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   map[string][]string
+	}{
+		{
+			name:   "schema",
+			schema: "{type: array, items: {type: object, properties: {id: {type: integer}}}}",
+			want: map[string][]string{
+				"pets.rb":      {"Pets = T.type_alias { T::Array[PetsItem]}", "require_relative './pets_item'"},
+				"pets_item.rb": {"class PetsItem", "const :id, T.nilable(Integer)"},
+			},
+		},
+		{
+			name:   "property",
+			schema: "{type: object, properties: {tags: {type: array, items: {type: object, properties: {id: {type: integer}}}}}}",
+			want: map[string][]string{
+				"pets.rb":           {"const :tags, T.nilable(T::Array[PetsTagsItem])", "require_relative './pets_tags_item'"},
+				"pets_tags_item.rb": {"class PetsTagsItem", "const :id, T.nilable(Integer)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas("\n    Pets: "+tt.schema+"\n"), Options{})
+
+			for file, want := range tt.want {
+				assertContains(t, files, file, want...)
+			}
+		})
+	}
+}
+
+func TestCircularReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		schemas  string
+		file     string
+		want     []string
+		warnings []string
+	}{
+		{
+			name: "array of itself",
+			schemas: `
+    Node:
+      type: object
+      properties:
+        children: {type: array, items: {$ref: '#/components/schemas/Node'}}
+`,
+			file: "node.rb",
+			want: []string{"const :children, T.nilable(T::Array[Node])"},
+		},
+		{
+			name: "required reference to itself",
+			schemas: `
+    Node:
+      type: object
+      required: [parent]
+      properties:
+        parent: {$ref: '#/components/schemas/Node'}
+`,
+			file:     "node.rb",
+			want:     []string{"const :parent, T.nilable(Node)"},
+			warnings: []string{"Node.parent is a circular reference to Node, so will be nilable"},
+		},
+		{
+			name: "allOf itself",
+			schemas: `
+    Node:
+      allOf:
+        - $ref: '#/components/schemas/Node'
+        - type: object
+          properties:
+            name: {type: string}
+`,
+			file:     "node.rb",
+			want:     []string{"const :name, T.nilable(String)"},
+			warnings: []string{"Node has a circular reference to Node through allOf, which will be skipped"},
+		},
+		{
+			name: "reference to itself",
+			schemas: `
+    TreeNode:
+      type: object
+      properties:
+        parent: {$ref: '#/components/schemas/TreeNode'}
+`,
+			file: "tree_node.rb",
+			want: []string{"const :parent, T.nilable(TreeNode)\n"},
+		},
+		{
+			name: "reference to itself wrapped in allOf",
+			schemas: `
+    TreeNode:
+      type: object
+      required: [parent]
+      properties:
+        parent:
+          description: The node above this one
+          allOf:
+            - $ref: '#/components/schemas/TreeNode'
+`,
+			file:     "tree_node.rb",
+			want:     []string{"# The node above this one\n  const :parent, T.nilable(TreeNode)\n"},
+			warnings: []string{"TreeNode.parent is a circular reference to TreeNode, so will be nilable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, logs := generateSpecLogs(t, specWithSchemas(tt.schemas), Options{})
+
+			assertContains(t, files, tt.file, tt.want...)
+			for _, w := range tt.warnings {
+				if !strings.Contains(logs, w) {
+					t.Errorf("didn't log %q:\n%s", w, logs)
+				}
+			}
+		})
+	}
+}
+
+func TestStringFormatTypes(t *testing.T) {
+	schema := `
+    Event:
+      type: object
+      properties:
+        at: {type: string, format: date-time}
+        on: {type: string, format: date}
+        starts: {type: string, format: time}
+        id: {type: string, format: uuid}
+        contact: {type: string, format: email}
+        tags: {type: array, items: {type: string, format: uuid}}
+    Timestamp: {type: string, format: date-time}
+`
+
+	tests := []struct {
+		name string
+		opts Options
+		want map[string][]string
+	}{
+		{
+			name: "default",
+			want: map[string][]string{
+				"event.rb": {
+					"const :at, T.nilable(String)",
+					"const :on, T.nilable(String)",
+					"const :starts, T.nilable(String)",
+					"const :id, T.nilable(String)",
+					"const :contact, T.nilable(String)",
+					"const :tags, T.nilable(T::Array[String])",
+				},
+				"timestamp.rb": {"Timestamp = T.type_alias { String}"},
+			},
+		},
+		{
+			name: "date-time",
+			opts: Options{DateTimeType: "DateTime"},
+			want: map[string][]string{
+				"event.rb":     {"const :at, T.nilable(DateTime)", "const :on, T.nilable(String)"},
+				"timestamp.rb": {"Timestamp = T.type_alias { DateTime}"},
+			},
+		},
+		{
+			name: "formats",
+			opts: Options{StringFormatTypes: map[string]string{
+				"date":  "Date",
+				"time":  "T.untyped",
+				"uuid":  "UUID",
+				"email": "Email",
+			}},
+			want: map[string][]string{
+				"event.rb": {
+					"const :at, T.nilable(String)",
+					"const :on, T.nilable(Date)",
+					"const :starts, T.nilable(T.untyped)",
+					"const :id, T.nilable(UUID)",
+					"const :contact, T.nilable(Email)",
+					"const :tags, T.nilable(T::Array[UUID])",
+				},
+			},
+		},
+		{
+			name: "date-time takes precedence",
+			opts: Options{DateTimeType: "DateTime", StringFormatTypes: map[string]string{"date-time": "Time"}},
+			want: map[string][]string{
+				"timestamp.rb": {"Timestamp = T.type_alias { DateTime}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(schema), tt.opts)
+
+			for file, want := range tt.want {
+				assertContains(t, files, file, want...)
+			}
+			// the configured types aren't generated, so mustn't be required
+			if strings.Contains(files["event.rb"], "require_relative './uuid'") {
+				t.Errorf("event.rb requires a format's type, which isn't generated:\n%s", files["event.rb"])
+			}
+		})
+	}
+}
+
+func TestSorbetTypeExtension(t *testing.T) {
+	files, logs := generateSpecLogs(t, specWithSchemas(`
+    Price:
+      type: string
+      format: money
+      x-sorbet-type: Money
+    Order:
+      type: object
+      required: [total]
+      properties:
+        total: {type: string, x-sorbet-type: Money}
+        placed: {type: string, nullable: true, x-sorbet-type: Time}
+        price: {$ref: '#/components/schemas/Price'}
+        note: {type: string, x-sorbet-type: 1}
+`), Options{})
+
+	assertContains(t, files, "price.rb", "Price = T.type_alias { Money}")
+	assertContains(t, files, "order.rb",
+		"const :total, Money",
+		"const :placed, T.nilable(Time)",
+		"const :price, T.nilable(Price)",
+		"const :note, T.nilable(String)",
+		"require_relative './price'",
+	)
+	if strings.Contains(files["order.rb"], "require_relative './money'") {
+		t.Errorf("order.rb requires the extension's type, which isn't generated:\n%s", files["order.rb"])
+	}
+
+	for _, w := range []string{
+		"Price has the x-sorbet-type extension, so will be generated as Money instead of its inferred type",
+		"Order.total has the x-sorbet-type extension, so will be generated as Money instead of its inferred type",
+		"WARN: Order.note has an invalid x-sorbet-type extension (`  1 `), which must be a non-empty string, so will be ignored",
+	} {
+		if !strings.Contains(logs, w) {
+			t.Errorf("didn't log %q:\n%s", w, logs)
+		}
+	}
+}
+
+func TestPropertyComments(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          description: The name of the pet
+        age:
+          type: integer
+          description: |
+            The age of the pet, in years.
+
+            Unknown for strays.
+        tags: {type: array, items: {type: string}}
+`), Options{})
+
+	// compare trimmed lines, so the assertions don't depend on the indentation of the output
+	var lines []string
+	for _, line := range strings.Split(files["pet.rb"], "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	tests := []struct {
+		property string
+		want     []string
+	}{
+		{
+			property: "const :name, T.nilable(String)",
+			want:     []string{"# The name of the pet"},
+		},
+		{
+			property: "const :age, T.nilable(Integer)",
+			want:     []string{"# The age of the pet, in years.", "#", "# Unknown for strays."},
+		},
+		{
+			property: "const :tags, T.nilable(T::Array[String])",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			i := slices.Index(lines, tt.property)
+			if i < 0 {
+				t.Fatalf("pet.rb doesn't contain %q:\n%s", tt.property, files["pet.rb"])
+			}
+			if i < len(tt.want) {
+				t.Fatalf("pet.rb doesn't have a comment above %q:\n%s", tt.property, files["pet.rb"])
+			}
+
+			got := lines[i-len(tt.want) : i]
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("pet.rb has the comment %q above %q, want %q", got, tt.property, tt.want)
+			}
+			if strings.HasPrefix(lines[i-len(tt.want)-1], "#") {
+				t.Errorf("pet.rb has additional comment lines above %q:\n%s", tt.property, files["pet.rb"])
+			}
+		})
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+        nickname: {type: string, deprecated: true}
+    LegacyPet:
+      type: object
+      deprecated: true
+      properties:
+        name: {type: string}
+    LegacyStatus:
+      type: string
+      deprecated: true
+`), Options{})
+
+	tests := []struct {
+		file   string
+		before string
+		want   bool
+	}{
+		{file: "pet.rb", before: "const :nickname, T.nilable(String)", want: true},
+		{file: "pet.rb", before: "const :name, T.nilable(String)"},
+		{file: "pet.rb", before: "class Pet"},
+		{file: "legacy_pet.rb", before: "class LegacyPet", want: true},
+		{file: "legacy_pet.rb", before: "const :name, T.nilable(String)"},
+		{file: "legacy_status.rb", before: "LegacyStatus = T.type_alias { String}", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+" "+tt.before, func(t *testing.T) {
+			var lines []string
+			for _, line := range strings.Split(files[tt.file], "\n") {
+				lines = append(lines, strings.TrimSpace(line))
+			}
+
+			i := slices.IndexFunc(lines, func(line string) bool { return strings.HasPrefix(line, tt.before) })
+			if i < 1 {
+				t.Fatalf("%s doesn't contain %q:\n%s", tt.file, tt.before, files[tt.file])
+			}
+			if got := lines[i-1] == "# @deprecated"; got != tt.want {
+				t.Errorf("%s has a deprecation above %q: %v, want %v:\n%s", tt.file, tt.before, got, tt.want, files[tt.file])
+			}
+		})
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	files, logs := generateSpecLogs(t, specWithSchemas(`
+    Settings:
+      type: object
+      required: [name, retries, required_null]
+      properties:
+        name: {type: string, default: "it's"}
+        retries: {type: integer, default: 3}
+        verbose: {type: boolean, default: false}
+        ratio: {type: number, default: 1}
+        tags: {type: array, items: {type: string}, default: [a, b]}
+        ids: {type: array, items: {type: integer}, default: []}
+        labels: {type: object, additionalProperties: {type: string}, default: {}}
+        note: {type: string, nullable: true, default: null}
+        plain: {type: string}
+        owner:
+          type: object
+          properties:
+            id: {type: integer}
+          default: {id: 1}
+        required_null: {type: string, default: null}
+`), Options{})
+
+	assertContains(t, files, "settings.rb",
+		`const :name, String, default: 'it\'s'`,
+		"const :retries, Integer, default: 3",
+		"const :verbose, T.nilable(T::Boolean), default: false",
+		"const :ratio, T.nilable(Float), default: 1.0",
+		"const :tags, T.nilable(T::Array[String]), default: ['a', 'b']",
+		"const :ids, T.nilable(T::Array[Integer]), default: []",
+		"const :labels, T.nilable(SettingsLabels), default: {}",
+		"const :note, T.nilable(String), default: nil",
+		"const :plain, T.nilable(String)\n",
+		"const :owner, T.nilable(SettingsOwner)\n",
+		"const :required_null, String\n",
+	)
+	for _, w := range []string{
+		"WARN: Settings.owner has an unsupported default (`  map[id:1] `), which will be ignored",
+		"WARN: Settings.required_null has a `null` default, but isn't nullable, so it will be ignored",
+	} {
+		if !strings.Contains(logs, w) {
+			t.Errorf("didn't log %q:\n%s", w, logs)
+		}
+	}
+}
+
+func TestMutable(t *testing.T) {
+	schema := `
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+    Owner:
+      type: object
+      x-sorbet-mutable: true
+      properties:
+        name: {type: string}
+    Tag:
+      type: object
+      x-sorbet-mutable: false
+      properties:
+        name: {type: string}
+    Labels:
+      type: object
+      properties:
+        name: {type: string}
+      additionalProperties: {type: string}
+`
+
+	tests := []struct {
+		name string
+		opts Options
+		want map[string]string
+	}{
+		{
+			name: "const",
+			want: map[string]string{
+				"pet.rb":   "const :name, String",
+				"owner.rb": "prop :name, T.nilable(String)",
+				"tag.rb":   "const :name, T.nilable(String)",
+			},
+		},
+		{
+			name: "mutable",
+			opts: Options{Mutable: true},
+			want: map[string]string{
+				"pet.rb":   "prop :name, String",
+				"owner.rb": "prop :name, T.nilable(String)",
+				"tag.rb":   "const :name, T.nilable(String)",
+			},
+		},
+		{
+			name: "mutable typed map",
+			opts: Options{Mutable: true, TypedMaps: true},
+			want: map[string]string{
+				"labels.rb": "prop :additional_properties, T::Hash[String, String], default: {}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(schema), tt.opts)
+
+			for file, want := range tt.want {
+				assertContains(t, files, file, want)
+			}
+		})
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Pet:
+      type: object
+      description: |
+        A pet, which has a description that spans
+        multiple lines, with `+"`code`"+`
+        =end
+        and tries to end the comment early.
+      properties:
+        "owner's name": {type: string}
+        'back\slash': {type: string}
+    Quote:
+      type: string
+      enum: ["it's", 'a\b']
+`), Options{})
+
+	assertContains(t, files, "pet.rb",
+		"Pet A pet, which has a description that spans\nmultiple lines, with `code`\n =end\nand tries to end the comment early.\n=end",
+		`name: 'owner\'s name'`,
+		`name: 'back\\slash'`,
+	)
+	assertContains(t, files, "quote.rb",
+		`Its = new('it\'s')`,
+		`Ab = new('a\\b')`,
+	)
+}
+
+func TestDiscriminator(t *testing.T) {
+	schemas := `
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: petType
+        mapping:
+          dog: '#/components/schemas/Dog'
+          cat: '#/components/schemas/Cat'
+    Dog:
+      type: object
+      properties:
+        bark: {type: boolean}
+    Cat:
+      type: object
+      required: [petType]
+      properties:
+        petType: {type: string}
+        lives: {type: integer}
+`
+
+	t.Run("file per type", func(t *testing.T) {
+		files := generateSpec(t, specWithSchemas(schemas), Options{})
+
+		assertContains(t, files, "pet.rb",
+			"module Pet",
+			"abstract!",
+			"def self.from_hash(hash)",
+			"case hash[:pet_type]",
+			"when 'cat' then Cat.from_hash(hash)",
+			"when 'dog' then Dog.from_hash(hash)",
+		)
+		if strings.Contains(files["pet.rb"], "sealed!") {
+			t.Errorf("pet.rb is sealed, but its members are in other files:\n%s", files["pet.rb"])
+		}
+		assertContains(t, files, "dog.rb", "include Pet", "const :pet_type, String, default: 'dog', name: 'petType'")
+		// the member's own declaration of the property is kept
+		assertContains(t, files, "cat.rb", "include Pet", "const :pet_type, String, name: 'petType'")
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		files := generateSpec(t, specWithSchemas(schemas), Options{SingleFile: "types.rb"})
+
+		assertContains(t, files, "types.rb", "module Pet", "sealed!")
+	})
+
+	t.Run("implicit mapping", func(t *testing.T) {
+		files := generateSpec(t, specWithSchemas(`
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: kind
+    Dog: {type: object, properties: {bark: {type: boolean}}}
+`), Options{})
+
+		assertContains(t, files, "pet.rb", "when 'Dog' then Dog.from_hash(hash)")
+		assertContains(t, files, "dog.rb", "const :kind, String, default: 'Dog'")
+	})
+
+	t.Run("non-struct member", func(t *testing.T) {
+		_, logs := generateSpecLogs(t, specWithSchemas(`
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Name'
+      discriminator:
+        propertyName: kind
+    Name: {type: string}
+`), Options{})
+
+		if want := `WARN: Pet has a discriminator mapping "Name" to Name, which isn't a struct, so can't include Pet`; !strings.Contains(logs, want) {
+			t.Errorf("didn't log %q:\n%s", want, logs)
+		}
+	})
+}
+
+func TestSerializers(t *testing.T) {
+	schema := `
+    Pet:
+      type: object
+      properties:
+        petName: {type: string}
+        owner:
+          type: object
+          properties:
+            id: {type: integer}
+`
+
+	tests := []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{name: "default"},
+		{name: "serializers", opts: Options{Serializers: true}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas(schema), tt.opts)
+
+			for _, file := range []string{"pet.rb", "pet_owner.rb"} {
+				if got := strings.Contains(files[file], "include T::Props::Serializable"); got != tt.want {
+					t.Errorf("%s includes T::Props::Serializable: %v, want %v:\n%s", file, got, tt.want, files[file])
+				}
+			}
+			assertContains(t, files, "pet.rb", "const :pet_name, T.nilable(String), name: 'petName'")
+		})
+	}
+}
+
+func TestStringConstraints(t *testing.T) {
+	schema := `
+    Account:
+      type: object
+      required: [code]
+      properties:
+        code: {type: string, pattern: '^[A-Z]{3}/\d+$', minLength: 4, maxLength: 10}
+        nickname: {type: string, maxLength: 20}
+        tags: {type: array, items: {type: string}}
+`
+
+	t.Run("annotations", func(t *testing.T) {
+		files := generateSpec(t, specWithSchemas(schema), Options{})
+
+		assertContains(t, files, "account.rb",
+			`const :code, String # pattern: /^[A-Z]{3}\/\d+$/, minLength: 4, maxLength: 10`,
+			"const :nickname, T.nilable(String) # maxLength: 20",
+		)
+		if strings.Contains(files["account.rb"], "validate!") {
+			t.Errorf("account.rb has a validate! method without Validations:\n%s", files["account.rb"])
+		}
+	})
+
+	t.Run("validations", func(t *testing.T) {
+		files := generateSpec(t, specWithSchemas(schema), Options{Validations: true})
+
+		assertContains(t, files, "account.rb",
+			"  sig { void }\n  def validate!\n",
+			`raise ArgumentError, 'code must match /^[A-Z]{3}\\/\\d+$/' unless code.match?(/^[A-Z]{3}\/\d+$/)`,
+			"raise ArgumentError, 'code must be at least 4 characters' unless code.length >= 4",
+			"raise ArgumentError, 'code must be at most 10 characters' unless code.length <= 10",
+			"raise ArgumentError, 'nickname must be at most 20 characters' unless nickname.nil? || T.must(nickname).length <= 20",
+		)
+		assertContains(t, files, "hash_deserializable.rb", "instance.validate! if instance.respond_to?(:validate!)")
+	})
+
+	t.Run("nothing to validate", func(t *testing.T) {
+		files := generateSpec(t, specWithSchemas("\n    Pet: {type: object, properties: {name: {type: string}}}\n"), Options{Validations: true})
+
+		if strings.Contains(files["pet.rb"], "validate!") {
+			t.Errorf("pet.rb has a validate! method without any constraints:\n%s", files["pet.rb"])
+		}
+	})
+}
+
+func TestRubyRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: `^\d+$`, want: `/^\d+$/`},
+		{pattern: `a/b`, want: `/a\/b/`},
+		{pattern: `a\/b`, want: `/a\/b/`},
+		{pattern: `#{x}`, want: `/\#{x}/`},
+		{pattern: `#x`, want: `/#x/`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := rubyRegexp(tt.pattern); got != tt.want {
+				t.Errorf("rubyRegexp(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferredTypes(t *testing.T) {
+	files, logs := generateSpecLogs(t, specWithSchemas(`
+    Pet:
+      required: [name]
+      properties:
+        name: {type: string}
+        owner:
+          properties:
+            id: {type: integer}
+        status: {enum: [available, sold]}
+        tags: {items: {type: string}}
+        sizes: {type: array, items: {enum: [1, 2]}}
+    Colour: {enum: [red, green]}
+    Names: {items: {type: string}}
+    Anything: {description: Could be anything}
+`), Options{LogLevel: LogLevelDebug})
+
+	assertContains(t, files, "pet.rb",
+		"class Pet < T::Struct",
+		"const :name, String",
+		"const :owner, T.nilable(PetOwner)",
+		"const :status, T.nilable(PetStatus)",
+		"const :tags, T.nilable(T::Array[String])",
+		"const :sizes, T.nilable(T::Array[Integer])",
+	)
+	assertContains(t, files, "pet_owner.rb", "class PetOwner < T::Struct", "const :id, T.nilable(Integer)")
+	assertContains(t, files, "colour.rb", "class Colour < T::Enum", "Red = new('red')")
+	assertContains(t, files, "names.rb", "Names = T.type_alias { T::Array[String]}")
+	if _, ok := files["anything.rb"]; ok {
+		t.Errorf("anything.rb was generated, but its type can't be inferred:\n%s", files["anything.rb"])
+	}
+
+	for _, w := range []string{
+		"Pet has no Type, so has been inferred as object",
+		"Pet.owner has no Type, so has been inferred as object",
+		"Pet.status has no Type, so has been inferred as string",
+		"Pet.tags has no Type, so has been inferred as array",
+		"WARN: Anything has no Type, so will be skipped",
+	} {
+		if !strings.Contains(logs, w) {
+			t.Errorf("didn't log %q:\n%s", w, logs)
+		}
+	}
+}
+
+func TestTuples(t *testing.T) {
+	spec := strings.Replace(specWithSchemas(`
+    Point:
+      type: array
+      prefixItems:
+        - type: number
+        - type: number
+    Pet:
+      type: object
+      properties:
+        tag:
+          type: array
+          prefixItems:
+            - type: string
+            - type: object
+              properties:
+                id: {type: integer}
+        history:
+          type: array
+          prefixItems:
+            - type: string
+          items:
+            type: integer
+`), "3.0.0", "3.1.0", 1)
+
+	files := generateSpec(t, spec, Options{})
+	assertContains(t, files, "point.rb", "Point = T.type_alias { [Float, Float]}")
+	assertContains(t, files, "pet.rb",
+		"const :tag, T.nilable([String, PetTagItem2])",
+		"# A tuple of `[String]`, followed by any number of `Integer`, which Sorbet can't express as a fixed-arity type",
+		"const :history, T.nilable(T::Array[T.any(String, Integer)])",
+	)
+	assertContains(t, files, "pet_tag_item_2.rb", "class PetTagItem2 < T::Struct", "const :id, T.nilable(Integer)")
+}
+
+func TestNullEnum(t *testing.T) {
+	spec := specWithSchemas(`
+    Status:
+      type: string
+      enum: [active, inactive, null]
+    Pet:
+      type: object
+      required: [status, colour]
+      properties:
+        status: {$ref: '#/components/schemas/Status'}
+        colour:
+          type: string
+          enum: [red, null]
+`)
+
+	t.Run(EnumStyleTEnum, func(t *testing.T) {
+		files, logs := generateSpecLogs(t, spec, Options{})
+		assertContains(t, files, "status.rb", "Active = new('active')", "Inactive = new('inactive')")
+		assertContains(t, files, "pet.rb", "const :status, T.nilable(Status)", "const :colour, T.nilable(PetColour)")
+		assertContains(t, files, "pet_colour.rb", "Red = new('red')")
+		if strings.Contains(files["status.rb"]+files["pet_colour.rb"], "new(''") || strings.Contains(logs, "WARN") {
+			t.Errorf("null was treated as an enum value:\n%s\n%s\n%s", files["status.rb"], files["pet_colour.rb"], logs)
+		}
+	})
+
+	t.Run(EnumStyleAlias, func(t *testing.T) {
+		files := generateSpec(t, spec, Options{EnumStyle: EnumStyleAlias})
+		assertContains(t, files, "status.rb", "Status = T.type_alias { T.nilable(String)}")
+	})
+}
+
+func TestBinaryFormats(t *testing.T) {
+	spec := specWithSchemas(`
+    Upload:
+      type: string
+      format: binary
+    Pet:
+      type: object
+      properties:
+        photo: {type: string, format: binary}
+        thumbnail: {type: string, format: byte}
+        name: {type: string}
+`)
+
+	t.Run("default", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		assertContains(t, files, "upload.rb", "Binary data, such as a file upload", "Upload = T.type_alias { String}")
+		assertContains(t, files, "pet.rb",
+			"const :photo, T.nilable(String) # format: binary",
+			"const :thumbnail, T.nilable(String) # format: byte",
+		)
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{BinaryType: "StringIO", ByteType: "Base64String"})
+		assertContains(t, files, "upload.rb", "Upload = T.type_alias { StringIO}")
+		assertContains(t, files, "pet.rb",
+			"const :photo, T.nilable(StringIO)",
+			"const :thumbnail, T.nilable(Base64String)",
+			"const :name, T.nilable(String)",
+		)
+	})
+}
+
+func TestInterfaceBases(t *testing.T) {
+	spec := specWithSchemas(`
+    Named:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+    Dated:
+      type: object
+      properties:
+        createdAt: {type: string}
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - $ref: '#/components/schemas/Dated'
+        - type: object
+          properties:
+            species: {type: string}
+    Owner:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - $ref: '#/components/schemas/Dated'
+    Event:
+      type: object
+      properties:
+        dated: {$ref: '#/components/schemas/Dated'}
+`)
+
+	files := generateSpec(t, spec, Options{InterfaceBases: true})
+	assertContains(t, files, "named.rb",
+		"module Named\n  extend T::Sig\n  extend T::Helpers\n  interface!\n",
+		"  sig { abstract.returns(String) }\n  def name; end\n",
+	)
+	assertContains(t, files, "pet.rb", "class Pet < T::Struct", "  include Named\n", "  const :name, String\n", "  const :species, T.nilable(String)\n")
+	assertContains(t, files, "owner.rb", "  include Named\n")
+	// Dated is also the type of a property, so needs to remain a struct
+	assertContains(t, files, "dated.rb", "class Dated < T::Struct")
+	if strings.Contains(files["pet.rb"], "include Dated") {
+		t.Errorf("pet.rb includes Dated, which isn't an interface:\n%s", files["pet.rb"])
+	}
+
+	files = generateSpec(t, spec, Options{})
+	assertContains(t, files, "named.rb", "class Named < T::Struct")
+}
+
+func TestFreeFormObjects(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Metadata:
+      type: object
+      minProperties: 1
+      maxProperties: 10
+    Anything:
+      type: object
+      additionalProperties: true
+    Empty:
+      type: object
+      additionalProperties: false
+    Pet:
+      type: object
+      properties:
+        metadata: {type: object}
+`), Options{})
+
+	const hash = "T::Hash[T.any(Symbol, String), T.untyped]"
+	assertContains(t, files, "metadata.rb", "minProperties: 1, maxProperties: 10", "Metadata = T.type_alias { "+hash+" }")
+	assertContains(t, files, "anything.rb", "Anything = T.type_alias { "+hash+" }")
+	assertContains(t, files, "empty.rb", "class Empty < T::Struct")
+	assertContains(t, files, "pet.rb", "const :metadata, T.nilable(PetMetadata)")
+	assertContains(t, files, "pet_metadata.rb", "PetMetadata = T.type_alias { "+hash+" }")
+}
+
+func TestConst(t *testing.T) {
+	spec := strings.Replace(specWithSchemas(`
+    Status:
+      type: string
+      const: active
+    Pet:
+      type: object
+      properties:
+        kind: {type: string, const: dog}
+`), "3.0.0", "3.1.0", 1)
+
+	files := generateSpec(t, spec, Options{})
+	assertContains(t, files, "status.rb", "class Status < T::Enum", "Active = new('active')")
+	assertContains(t, files, "pet.rb", "const :kind, T.nilable(String)")
+
+	files = generateSpec(t, spec, Options{EnumStyle: EnumStyleAlias})
+	assertContains(t, files, "status.rb", "Status = T.type_alias { String}")
+}
+
+func TestPropertiesWithAdditionalProperties(t *testing.T) {
+	spec := specWithSchemas(`
+    Labels:
+      type: object
+      properties:
+        name: {type: string}
+      additionalProperties:
+        type: array
+        items: {type: string}
+`)
+
+	files := generateSpec(t, spec, Options{})
+	assertContains(t, files, "labels.rb",
+		"class Labels < T::Struct",
+		"  const :name, T.nilable(String)\n",
+		"  # Any properties that aren't declared above\n  const :additional_properties, T::Hash[T.any(Symbol, String), T::Array[String]], default: {}\n",
+	)
+	assertContains(t, files, "hash_deserializable.rb", "args[:additional_properties] = parse_value(extra, props[:additional_properties][:type_object])")
+
+	files = generateSpec(t, spec, Options{TypedMaps: true})
+	assertContains(t, files, "labels.rb", "const :additional_properties, T::Hash[String, T::Array[String]], default: {}")
+}
+
+func TestArrayWithoutItems(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "schema",
+			schema: "{type: array}",
+			want:   "Tags = T.type_alias { T::Array[T.untyped]}",
+		},
+		{
+			name:   "property",
+			schema: "{type: object, properties: {tags: {type: array}}}",
+			want:   "const :tags, T.nilable(T::Array[T.untyped])",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, logs := generateSpecLogs(t, specWithSchemas("\n    Tags: "+tt.schema+"\n"), Options{})
+			assertContains(t, files, "tags.rb", tt.want)
+
+			if want := "is an array without items, so its items will be treated as T.untyped"; !strings.Contains(logs, want) {
+				t.Errorf("didn't log %q:\n%s", want, logs)
+			}
+		})
+	}
+}
+
+func TestEmptyEnum(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "schema",
+			schema: "{type: string, enum: []}",
+			want:   "Status = T.type_alias { String}",
+		},
+		{
+			name:   "property",
+			schema: "{type: object, properties: {value: {type: string, enum: []}}}",
+			want:   "const :value, T.nilable(String)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := generateSpec(t, specWithSchemas("\n    Status: "+tt.schema+"\n"), Options{})
+			assertContains(t, files, "status.rb", tt.want)
+
+			if got := files["status.rb"]; strings.Contains(got, "T::Enum") {
+				t.Errorf("status.rb is generated as a T::Enum, without any values:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestAliasStyle(t *testing.T) {
+	spec := specWithSchemas(`
+    PetId:
+      type: string
+    Tags:
+      type: array
+      items: {type: string}
+    Pet:
+      type: object
+      properties:
+        id: {$ref: '#/components/schemas/PetId'}
+`)
+
+	files := generateSpec(t, spec, Options{AliasStyle: AliasStyleWrapper})
+	assertContains(t, files, "pet_id.rb",
+		"class PetId\n  extend T::Sig\n",
+		"  sig { returns(String) }\n  attr_reader :value\n",
+		"  def self.from_hash(value)\n    new(value)\n  end\n",
+	)
+	assertContains(t, files, "tags.rb", "  sig { params(value: T::Array[String]).void }\n")
+	assertContains(t, files, "pet.rb", "class Pet < T::Struct", "const :id, T.nilable(PetId)")
+
+	files = generateSpec(t, spec, Options{AliasStyle: AliasStyleWrapper, Format: FormatRBS})
+	assertContains(t, files, "pet_id.rbs", "class PetId\n  attr_reader value: String\n", "  def initialize: (String value) -> void\n")
+
+	files = generateSpec(t, spec, Options{})
+	assertContains(t, files, "pet_id.rb", "PetId = T.type_alias { String}")
+}
+
+func TestRole(t *testing.T) {
+	spec := specWithSchemas(`
+    User:
+      type: object
+      properties:
+        id: {type: integer, readOnly: true}
+        password: {type: string, writeOnly: true}
+        name: {type: string}
+`)
+
+	tests := []struct {
+		role    string
+		want    []string
+		notWant []string
+	}{
+		{
+			role: RoleBoth,
+			want: []string{
+				"  # Read only, so is only present in responses\n  const :id, T.nilable(Integer)\n",
+				"  # Write only, so is only present in requests\n  const :password, T.nilable(String)\n",
+			},
+		},
+		{
+			role:    RoleRequest,
+			want:    []string{"const :password, T.nilable(String)\n", "const :name, T.nilable(String)\n"},
+			notWant: []string{":id", "Write only"},
+		},
+		{
+			role:    RoleResponse,
+			want:    []string{"const :id, T.nilable(Integer)\n", "const :name, T.nilable(String)\n"},
+			notWant: []string{":password", "Read only"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			files := generateSpec(t, spec, Options{Role: tt.role})
+			assertContains(t, files, "user.rb", tt.want...)
+
+			for _, w := range tt.notWant {
+				if strings.Contains(files["user.rb"], w) {
+					t.Errorf("user.rb contains %q:\n%s", w, files["user.rb"])
+				}
+			}
+		})
+	}
+}
+
+func TestExamples(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      description: A pet
+      example: {name: Rex, age: 3}
+      properties:
+        name: {type: string, example: foo}
+        owner: {$ref: '#/components/schemas/Owner'}
+    Owner:
+      type: string
+      example: Alice
+`)
+
+	files := generateSpec(t, spec, Options{Examples: true})
+	assertContains(t, files, "pet.rb",
+		"A pet\n\nExample: {\"age\":3,\"name\":\"Rex\"}\n=end",
+		"  # Example: \"foo\"\n  const :name, T.nilable(String)\n",
+		"\n  const :owner, T.nilable(Owner)\n",
+	)
+	assertContains(t, files, "owner.rb", "Example: \"Alice\"")
+
+	files = generateSpec(t, spec, Options{})
+	if strings.Contains(files["pet.rb"], "Example:") {
+		t.Errorf("pet.rb contains examples, despite Examples being disabled:\n%s", files["pet.rb"])
+	}
+}
+
+func TestInlineEnums(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        status:
+          type: string
+          enum: [available, sold]
+          default: sold
+        kind:
+          type: string
+          enum: [dog, cat]
+          default: fish
+        name:
+          type: string
+`)
+
+	files, logs := generateSpecLogs(t, spec, Options{})
+	assertContains(t, files, "pet_status.rb", "class PetStatus < T::Enum", "Available = new('available')")
+	assertContains(t, files, "pet.rb",
+		"const :status, T.nilable(PetStatus), default: PetStatus::Sold",
+		"const :kind, T.nilable(PetKind)\n",
+		"const :name, T.nilable(String)",
+	)
+	if _, ok := files["pet_name.rb"]; ok {
+		t.Errorf("pet_name.rb was generated, but name has no enum")
+	}
+	if want := "WARN: Pet.kind has a default"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+
+	files = generateSpec(t, spec, Options{EnumStyle: EnumStyleAlias})
+	assertContains(t, files, "pet.rb", "const :status, T.nilable(String)")
+	if _, ok := files["pet_status.rb"]; ok {
+		t.Errorf("pet_status.rb was generated, but EnumStyle is alias")
+	}
+}
+
+func TestEnumVarNames(t *testing.T) {
+	spec := specWithSchemas(`
+    Priority:
+      type: integer
+      enum: [1, 2, 3]
+      x-enum-varnames: [Low, medium, High]
+    Size:
+      type: string
+      enum: [s, m, l]
+      x-enum-varnames: [Small, Medium]
+`)
+
+	files, logs := generateSpecLogs(t, spec, Options{})
+	assertContains(t, files, "priority.rb", "Low = new(1)", "Medium = new(2)", "High = new(3)")
+	assertContains(t, files, "size.rb", "Small = new('s')", "Medium = new('m')", "L = new('l')")
+	if want := "WARN: Size has 2 values in its x-enum-varnames extension, but 3 enum values"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+}
+
+func TestEnumDescriptions(t *testing.T) {
+	spec := specWithSchemas(`
+    Size:
+      type: string
+      enum: [s, m, l]
+      x-enum-descriptions: [Small, "Medium, the default"]
+`)
+
+	files, logs := generateSpecLogs(t, spec, Options{})
+	assertContains(t, files, "size.rb",
+		"    # Small\n    S = new('s')\n",
+		"    # Medium, the default\n    M = new('m')\n",
+		"    M = new('m')\n    L = new('l')\n",
+	)
+	if want := "WARN: Size has 2 values in its x-enum-descriptions extension, but 3 enum values"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+
+	files = generateSpec(t, spec, Options{Format: FormatRBI})
+	assertContains(t, files, "size.rbi", "    # Small\n    S = new('s')\n")
+}
+
+func TestNumericConstraints(t *testing.T) {
+	tests := []struct {
+		name     string
+		property string
+		want     []string
+	}{
+		{
+			name:     "integer",
+			property: "{type: integer, minimum: 0, maximum: 100}",
+			want: []string{
+				"# minimum: 0, maximum: 100",
+				"unless value.nil? || T.must(value) >= 0",
+				"unless value.nil? || T.must(value) <= 100",
+			},
+		},
+		{
+			name:     "multipleOf",
+			property: "{type: integer, minimum: 1, maximum: 10, multipleOf: 2}",
+			want: []string{
+				"# minimum: 1, maximum: 10, multipleOf: 2",
+				"unless value.nil? || (T.must(value) % 2).zero?",
+			},
+		},
+		{
+			name:     "fractional number",
+			property: "{type: number, minimum: 0.5, multipleOf: 0.01}",
+			want: []string{
+				"# minimum: 0.5, multipleOf: 0.01",
+				"unless value.nil? || T.must(value) >= 0.5",
+				"unless value.nil? || (T.must(value).to_s.to_r % 0.01r).zero?",
+			},
+		},
+		{
+			name:     "exponent",
+			property: "{type: number, maximum: 1e3}",
+			want: []string{
+				"# maximum: 1000",
+				"unless value.nil? || T.must(value) <= 1000",
+			},
+		},
+		{
+			name:     "exclusive bounds",
+			property: "{type: number, minimum: 0.25, exclusiveMinimum: true, maximum: 2.5, exclusiveMaximum: true}",
+			want: []string{
+				"# exclusiveMinimum: 0.25, exclusiveMaximum: 2.5",
+				"unless value.nil? || T.must(value) > 0.25",
+				"unless value.nil? || T.must(value) < 2.5",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := specWithSchemas(`
+    Measurement:
+      type: object
+      properties:
+        value: ` + tt.property + `
+`)
+			files := generateSpec(t, spec, Options{Validations: true})
+			assertContains(t, files, "measurement.rb", tt.want...)
+		})
+	}
+
+	files := generateSpec(t, specWithSchemas("\n    Percentage: {type: integer, minimum: 0, maximum: 100}\n"), Options{})
+	assertContains(t, files, "percentage.rb", "minimum: 0, maximum: 100", "Percentage = T.type_alias { Integer}")
+}
+
+func TestUUIDs(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string, format: uuid}
+        ownerId: {type: string, format: uuid}
+`)
+
+	files := generateSpec(t, spec, Options{Validations: true})
+	assertContains(t, files, "pet.rb",
+		"const :id, String",
+		"const :owner_id, T.nilable(String), name: 'ownerId'",
+		`raise ArgumentError, 'id must be a UUID' unless id.match?(/\A\h{8}-\h{4}-\h{4}-\h{4}-\h{12}\z/)`,
+		`raise ArgumentError, 'owner_id must be a UUID' unless owner_id.nil? || T.must(owner_id).match?(/\A\h{8}-\h{4}-\h{4}-\h{4}-\h{12}\z/)`,
+	)
+
+	files = generateSpec(t, spec, Options{Validations: true, UUIDType: "UUID", StringFormatTypes: map[string]string{"uuid": "Ignored"}})
+	assertContains(t, files, "pet.rb", "const :id, UUID", "const :owner_id, T.nilable(UUID), name: 'ownerId'")
+	if strings.Contains(files["pet.rb"], "must be a UUID") {
+		t.Errorf("pet.rb validates UUIDs which aren't generated as a String:\n%s", files["pet.rb"])
+	}
+}
+
+func TestRefAliases(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+    Animal: {$ref: '#/components/schemas/Pet'}
+    Creature: {$ref: '#/components/schemas/Animal'}
+    PetId: {type: string}
+    Identifier: {$ref: '#/components/schemas/PetId'}
+    Owner:
+      type: object
+      properties:
+        pet: {$ref: '#/components/schemas/Animal'}
+`)
+
+	files := generateSpec(t, spec, Options{})
+	assertContains(t, files, "animal.rb", "Animal = Pet")
+	assertContains(t, files, "creature.rb", "Creature = Animal")
+	assertContains(t, files, "identifier.rb", "Identifier = T.type_alias { PetId}")
+	assertContains(t, files, "owner.rb", "const :pet, T.nilable(Animal)")
+
+	files = generateSpec(t, spec, Options{AliasStyle: AliasStyleWrapper})
+	assertContains(t, files, "identifier.rb", "Identifier = PetId")
+}
+
+func TestNullableArrays(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [tags, nicknames, aliases]
+      properties:
+        tags:
+          type: array
+          nullable: true
+          items: {type: string}
+        nicknames:
+          type: array
+          items: {type: string, nullable: true}
+        aliases:
+          type: array
+          items: {type: [string, "null"]}
+    Tags:
+      type: array
+      nullable: true
+      items: {type: string}
+`)
+
+	files := generateSpec(t, spec, Options{})
+	assertContains(t, files, "pet.rb",
+		"const :tags, T.nilable(T::Array[String])",
+		"const :nicknames, T::Array[T.nilable(String)]",
+		"const :aliases, T::Array[T.nilable(String)]",
+	)
+	assertContains(t, files, "tags.rb", "Tags = T.type_alias { T.nilable(T::Array[String])}")
+}
+
+func TestNotSchemas(t *testing.T) {
+	spec := specWithSchemas(`
+    NotString:
+      description: Anything but a string
+      not: {type: string}
+    Pet:
+      type: object
+      properties:
+        tag:
+          not: {type: integer}
+`)
+
+	files, logs := generateSpecLogs(t, spec, Options{})
+	assertContains(t, files, "not_string.rb",
+		"Anything but a string",
+		"Any value which doesn't match a `not` schema, which Sorbet can't express, so is untyped",
+		"NotString = T.type_alias { T.untyped}",
+	)
+	assertContains(t, files, "pet.rb",
+		"  # Any value which doesn't match a `not` schema, which Sorbet can't express, so is untyped\n",
+		"const :tag, T.nilable(T.untyped)",
+	)
+	for _, want := range []string{
+		"WARN: NotString is a `not` schema, which Sorbet can't express, so will be treated as T.untyped",
+		"WARN: Pet.tag is a `not` schema",
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("didn't log %q:\n%s", want, logs)
+		}
+	}
+}
+
+func TestCollectionDefaults(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [names]
+      properties:
+        tags: {type: array, items: {type: string}}
+        names: {type: array, items: {type: string}}
+        nullable_tags: {type: array, nullable: true, items: {type: string}}
+        sizes: {type: array, items: {type: integer}, default: [1]}
+        labels: {type: object, additionalProperties: {type: string}}
+        owner: {type: object, properties: {id: {type: integer}}}
+`)
+
+	t.Run("enabled", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{CollectionDefaults: true})
+		assertContains(t, files, "pet.rb",
+			"const :tags, T::Array[String], default: []\n",
+			"const :names, T::Array[String]\n",
+			"const :nullable_tags, T.nilable(T::Array[String])\n",
+			"const :sizes, T.nilable(T::Array[Integer]), default: [1]\n",
+			"const :labels, PetLabels, default: {}\n",
+			"const :owner, T.nilable(PetOwner)\n",
+		)
+		assertContains(t, files, "hash_deserializable.rb", "type_info.key?(:default)")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		assertContains(t, files, "pet.rb", "const :tags, T.nilable(T::Array[String])\n", "const :labels, T.nilable(PetLabels)\n")
+	})
+}
+
+func TestExtendSig(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+    Limited:
+      type: object
+      properties:
+        name: {type: string, maxLength: 10}
+    Status:
+      type: string
+      enum: [available, sold]
+    PetId:
+      type: string
+`)
+
+	for _, format := range []string{FormatRB, FormatRBI} {
+		t.Run(format, func(t *testing.T) {
+			files := generateSpec(t, spec, Options{AliasStyle: AliasStyleWrapper, Validations: true, Format: format})
+			assertContains(t, files, "pet_id."+format, "class PetId\n  extend T::Sig\n")
+			assertContains(t, files, "limited."+format, "class Limited < T::Struct\n  extend T::Sig\n")
+			for _, file := range []string{"pet." + format, "status." + format} {
+				if strings.Contains(files[file], "extend T::Sig") {
+					t.Errorf("%s extends T::Sig, but declares no signatures:\n%s", file, files[file])
+				}
+			}
+		})
+	}
+}
+
+func TestInflect(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      properties:
+        tags:
+          type: array
+          items: {type: object, properties: {name: {type: string}}}
+        categories:
+          type: array
+          items: {type: object, properties: {id: {type: integer}}}
+        owners:
+          type: array
+          items: {type: object, properties: {id: {type: integer}}}
+    PetOwner:
+      type: object
+      properties:
+        id: {type: integer}
+`)
+
+	t.Run("enabled", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{Inflect: true})
+		assertContains(t, files, "pet.rb",
+			"const :tags, T.nilable(T::Array[PetTag])",
+			"const :categories, T.nilable(T::Array[PetCategory])",
+			"const :owners, T.nilable(T::Array[PetOwnersItem])",
+		)
+		assertContains(t, files, "pet_tag.rb", "class PetTag < T::Struct")
+		assertContains(t, files, "pet_category.rb", "class PetCategory < T::Struct")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		assertContains(t, files, "pet.rb", "const :tags, T.nilable(T::Array[PetTagsItem])")
+	})
+}
+
+func TestDecimalType(t *testing.T) {
+	spec := specWithSchemas(`
+    Price:
+      type: object
+      required: [amount]
+      properties:
+        amount: {type: string, format: decimal}
+        rate: {type: number, format: decimal}
+        total: {type: number}
+    Amount:
+      type: string
+      format: decimal
+`)
+
+	t.Run("default", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		assertContains(t, files, "price.rb",
+			"# An arbitrary-precision decimal, which needs `require 'bigdecimal'`\n  const :amount, BigDecimal # format: decimal\n",
+			"const :rate, T.nilable(BigDecimal) # format: decimal\n",
+			"const :total, T.nilable(Float)\n",
+		)
+		assertContains(t, files, "amount.rb", "An arbitrary-precision decimal, which needs `require 'bigdecimal'`", "Amount = T.type_alias { BigDecimal}")
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{DecimalType: "Money"})
+		assertContains(t, files, "price.rb", "const :amount, Money # format: decimal\n", "const :rate, T.nilable(Money) # format: decimal\n")
+		if strings.Contains(files["price.rb"], "bigdecimal") {
+			t.Errorf("price.rb notes that bigdecimal must be required, but the DecimalType isn't BigDecimal:\n%s", files["price.rb"])
+		}
+	})
+}
+
+func TestSkipExtension(t *testing.T) {
+	spec := specWithSchemas(`
+    Address:
+      type: object
+      x-sorbet-skip: true
+      properties:
+        line1: {type: string}
+    Pet:
+      type: object
+      properties:
+        address: {$ref: '#/components/schemas/Address'}
+        zebras: {type: array, items: {$ref: '#/components/schemas/Zebra'}}
+    Zebra:
+      type: object
+      x-sorbet-skip: true
+    Kept:
+      type: object
+      x-sorbet-skip: false
+`)
+
+	files, logs := generateSpecLogs(t, spec, Options{})
+	assertContains(t, files, "pet.rb", "const :address, T.nilable(Address)", "const :zebras, T.nilable(T::Array[Zebra])")
+	if strings.Contains(files["pet.rb"], "require_relative './address'") || strings.Contains(files["pet.rb"], "require_relative './zebra'") {
+		t.Errorf("pet.rb requires a skipped schema's file:\n%s", files["pet.rb"])
+	}
+	for _, file := range []string{"address.rb", "zebra.rb"} {
+		if _, ok := files[file]; ok {
+			t.Errorf("%s was generated, but its schema has the x-sorbet-skip extension", file)
+		}
+	}
+	assertContains(t, files, "kept.rb", "Kept = T.type_alias")
+
+	for _, want := range []string{"Address has the x-sorbet-skip extension, so won't be generated", "Zebra has the x-sorbet-skip extension"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("didn't log %q:\n%s", want, logs)
+		}
+	}
+}
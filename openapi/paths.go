@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// nonAlphanumericChars matches the characters of a path which can't be used in a schema name, i.e. `/` and `{`
+var nonAlphanumericChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// pathSchemas returns the inline schemas of the request and response bodies of each operation under `paths`, named
+// after the operation, i.e. `createUser_request` or `createUser_201_response`. Bodies which are a `$ref` are skipped,
+// as they're already generated from the components they refer to
+func pathSchemas(log *logger, paths *v3.Paths) map[string]*base.SchemaProxy {
+	schemas := make(map[string]*base.SchemaProxy)
+	if paths == nil {
+		return schemas
+	}
+
+	for _, path := range sortedKeys(paths.PathItems) {
+		for _, o := range pathOperations(paths.PathItems[path]) {
+			name := operationName(o.method, path, o.operation)
+
+			if o.operation.RequestBody != nil {
+				addBodySchema(log, schemas, name+"_request", o.operation.RequestBody.Content)
+			}
+
+			if o.operation.Responses != nil {
+				for _, code := range sortedKeys(o.operation.Responses.Codes) {
+					addBodySchema(log, schemas, name+"_"+code+"_response", o.operation.Responses.Codes[code].Content)
+				}
+				if o.operation.Responses.Default != nil {
+					addBodySchema(log, schemas, name+"_default_response", o.operation.Responses.Default.Content)
+				}
+			}
+		}
+	}
+
+	return schemas
+}
+
+// responseSchemas returns a string enum schema for each operation under `paths`, of the status codes declared in its
+// `responses`, including `default`, named after the operation, i.e. `createUser_responses`
+func responseSchemas(paths *v3.Paths) map[string]*base.SchemaProxy {
+	schemas := make(map[string]*base.SchemaProxy)
+	if paths == nil {
+		return schemas
+	}
+
+	for _, path := range sortedKeys(paths.PathItems) {
+		for _, o := range pathOperations(paths.PathItems[path]) {
+			if o.operation.Responses == nil {
+				continue
+			}
+
+			var codes []any
+			for _, code := range sortedKeys(o.operation.Responses.Codes) {
+				codes = append(codes, code)
+			}
+			if o.operation.Responses.Default != nil {
+				codes = append(codes, "default")
+			}
+			if len(codes) == 0 {
+				continue
+			}
+
+			name := operationName(o.method, path, o.operation)
+			schemas[name+"_responses"] = base.CreateSchemaProxy(&base.Schema{
+				Type:        []string{"string"},
+				Description: fmt.Sprintf("The response status codes declared by %s %s", strings.ToUpper(o.method), path),
+				Enum:        codes,
+			})
+		}
+	}
+
+	return schemas
+}
+
+// pathOperation is an operation of a path, with the HTTP method it's for
+type pathOperation struct {
+	method    string
+	operation *v3.Operation
+}
+
+// pathOperations returns the operations that a path declares
+func pathOperations(item *v3.PathItem) []pathOperation {
+	var operations []pathOperation
+	for _, o := range []pathOperation{
+		{"get", item.Get},
+		{"put", item.Put},
+		{"post", item.Post},
+		{"delete", item.Delete},
+		{"options", item.Options},
+		{"head", item.Head},
+		{"patch", item.Patch},
+		{"trace", item.Trace},
+	} {
+		if o.operation != nil {
+			operations = append(operations, o)
+		}
+	}
+	return operations
+}
+
+// operationName returns the name to generate an operation's bodies after, which is its `operationId`, or if it
+// doesn't have one, its method and path, i.e. `post_users_id` for `POST /users/{id}`
+func operationName(method string, path string, operation *v3.Operation) string {
+	if operation.OperationId != "" {
+		return operation.OperationId
+	}
+	return strings.TrimSuffix(method+"_"+nonAlphanumericChars.ReplaceAllString(strings.TrimPrefix(path, "/"), "_"), "_")
+}
+
+// addBodySchema adds the inline schema of a request or response body to schemas, if it has one. When a body has
+// different schemas for multiple media types, a schema is added for each, suffixed with its media type, i.e.
+// `createUser_request_application_xml`. Media types whose schema is the same `$ref`, or an identical inline schema,
+// as that of another media type only count once, and `$ref`s to components are skipped, as they're already generated
+func addBodySchema(log *logger, schemas map[string]*base.SchemaProxy, name string, content map[string]*v3.MediaType) {
+	type bodySchema struct {
+		mediaType string
+		sp        *base.SchemaProxy
+	}
+
+	var bodies []bodySchema
+	seen := make(map[string]bool)
+	for _, mediaType := range sortedKeys(content) {
+		sp := content[mediaType].Schema
+		if sp == nil {
+			continue
+		}
+
+		key := bodySchemaKey(sp)
+		if seen[key] {
+			log.debugf("Skipping the %s body of %s, as it has the same schema as another media type", mediaType, name)
+			continue
+		}
+		seen[key] = true
+		bodies = append(bodies, bodySchema{mediaType, sp})
+	}
+
+	for _, b := range bodies {
+		if isSchemaRef(b.sp) {
+			continue
+		}
+
+		schemaName := name
+		if len(bodies) > 1 {
+			schemaName = name + "_" + mediaTypeName(b.mediaType)
+		}
+		log.infof("Generating %s from the %s body of the operation", schemaName, b.mediaType)
+		schemas[schemaName] = b.sp
+	}
+}
+
+// bodySchemaKey returns the `$ref` of a body's schema, or otherwise the YAML of the inline schema, so that media types
+// sharing a schema can be detected
+func bodySchemaKey(sp *base.SchemaProxy) string {
+	if sp.IsReference() {
+		return "$ref:" + sp.GetReference()
+	}
+	if node := sp.GoLow().GetValueNode(); node != nil {
+		if out, err := yaml.Marshal(node); err == nil {
+			return string(out)
+		}
+	}
+	return fmt.Sprintf("%p", sp)
+}
+
+// mediaTypeName returns a media type, without any parameters, with the characters that can't be used in a schema name
+// replaced, i.e. `application/vnd.api+json; charset=utf-8` will be `application_vnd_api_json`
+func mediaTypeName(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return strings.Trim(nonAlphanumericChars.ReplaceAllString(mediaType, "_"), "_")
+}
+
+// mergePathSchemas adds the schemas from pathSchemas to the component schemas, returning a new map. Any whose name
+// is already used by a component is skipped
+func mergePathSchemas(log *logger, components map[string]*base.SchemaProxy, paths map[string]*base.SchemaProxy) map[string]*base.SchemaProxy {
+	schemas := make(map[string]*base.SchemaProxy, len(components)+len(paths))
+	for k, v := range components {
+		schemas[k] = v
+	}
+
+	for k, v := range paths {
+		if _, ok := schemas[k]; ok {
+			log.warnf("Skipping the body schema %s, as a component of the same name exists", k)
+			continue
+		}
+		schemas[k] = v
+	}
+
+	return schemas
+}
@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  message: {type: string}
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: integer}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name: {type: string}
+    createUser_request:
+      type: string
+`
+
+	t.Run("with Paths", func(t *testing.T) {
+		files, logs := generateSpecLogs(t, spec, Options{Paths: true})
+		assertContains(t, files, "create_user_default_response.rb", "class CreateUserDefaultResponse < T::Struct", "const :message, T.nilable(String)")
+		assertContains(t, files, "get_users_id_200_response.rb", "class GetUsersId200Response < T::Struct", "const :id, T.nilable(Integer)")
+		assertContains(t, files, "create_user_request.rb", "CreateUserRequest = T.type_alias { String}")
+		if _, ok := files["create_user_201_response.rb"]; ok {
+			t.Errorf("create_user_201_response.rb was generated, but the body is a $ref to User")
+		}
+
+		if want := "WARN: Skipping the body schema createUser_request, as a component of the same name exists"; !strings.Contains(logs, want) {
+			t.Errorf("didn't log %q:\n%s", want, logs)
+		}
+	})
+
+	t.Run("without Paths", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		for _, f := range []string{"create_user_default_response.rb", "get_users_id_200_response.rb"} {
+			if _, ok := files[f]; ok {
+				t.Errorf("%s was generated, but Paths isn't enabled", f)
+			}
+		}
+	})
+}
+
+func TestResponsesEnum(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        "200": {description: OK}
+        "404": {description: Not Found}
+        default: {description: Error}
+components:
+  schemas: {}
+`
+
+	files := generateSpec(t, spec, Options{ResponsesEnum: true})
+	assertContains(t, files, "get_pet_responses.rb",
+		"The response status codes declared by GET /pets/{id}",
+		"class GetPetResponses < T::Enum",
+		"    Value200 = new('200')\n",
+		"    Value404 = new('404')\n",
+		"    Default = new('default')\n",
+	)
+}
+
+func TestPathsMediaTypes(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+          application/xml:
+            schema:
+              type: object
+              properties:
+                fullName: {type: string}
+          application/vnd.api+json; charset=utf-8:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+            application/xml:
+              schema: {$ref: '#/components/schemas/User'}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name: {type: string}
+`
+
+	files, logs := generateSpecLogs(t, spec, Options{Paths: true, LogLevel: LogLevelDebug})
+	assertContains(t, files, "create_user_request_application_json.rb", "class CreateUserRequestApplicationJson < T::Struct", "const :name, T.nilable(String)")
+	assertContains(t, files, "create_user_request_application_xml.rb", "class CreateUserRequestApplicationXml < T::Struct", "const :full_name, T.nilable(String)")
+	for _, f := range []string{"create_user_request.rb", "create_user_request_application_vnd_api_json.rb", "create_user_200_response.rb"} {
+		if _, ok := files[f]; ok {
+			t.Errorf("%s was generated, but its schema is the same as another media type's, or a $ref", f)
+		}
+	}
+	if want := "Skipping the application/vnd.api+json; charset=utf-8 body of createUser_request"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+}
+
+func TestMediaTypeName(t *testing.T) {
+	tests := map[string]string{
+		"application/json":                        "application_json",
+		"application/vnd.api+json; charset=utf-8": "application_vnd_api_json",
+		"text/plain":                              "text_plain",
+	}
+
+	for mediaType, want := range tests {
+		if got := mediaTypeName(mediaType); got != want {
+			t.Errorf("mediaTypeName(%q) = %q, want %q", mediaType, got, want)
+		}
+	}
+}
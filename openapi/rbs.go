@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// rbsAliases returns the RBS name of each Type that is generated as a type alias, which unlike Sorbet, RBS requires to
+// be lower case, i.e. `PetId` will be `pet_id`
+func rbsAliases(types []Type) map[string]string {
+	aliases := make(map[string]string)
+	for _, t := range types {
+		if t.IsAlias() {
+			aliases[t.TypeName] = strcase.ToSnake(t.TypeName)
+		}
+	}
+	return aliases
+}
+
+// rbsType converts a Sorbet type to its RBS equivalent, i.e. `T.nilable(T::Array[String])` will be `Array[String]?`.
+// References to the given aliases are renamed to their RBS names. Anything that can't be converted is `untyped`
+func rbsType(ty string, aliases map[string]string) string {
+	r := rbsTypeReader{s: ty, aliases: aliases}
+	converted, ok := r.read()
+	if !ok || r.pos != len(r.s) {
+		return "untyped"
+	}
+	return converted
+}
+
+// rbsTypeReader reads a Sorbet type, converting it to RBS as it goes
+type rbsTypeReader struct {
+	s       string
+	pos     int
+	aliases map[string]string
+}
+
+func (r *rbsTypeReader) skipSpaces() {
+	for r.pos < len(r.s) && r.s[r.pos] == ' ' {
+		r.pos++
+	}
+}
+
+// read reads a single type, such as `String`, `T::Array[String]`, `T.any(A, B)` or a tuple of `[A, B]`
+func (r *rbsTypeReader) read() (string, bool) {
+	r.skipSpaces()
+
+	if r.consume('[') {
+		members, ok := r.readList(']')
+		if !ok {
+			return "", false
+		}
+		return "[" + strings.Join(members, ", ") + "]", true
+	}
+
+	start := r.pos
+	for r.pos < len(r.s) && !strings.ContainsRune("[](), ", rune(r.s[r.pos])) {
+		r.pos++
+	}
+	name := r.s[start:r.pos]
+	if name == "" {
+		return "", false
+	}
+
+	switch {
+	case r.consume('['):
+		args, ok := r.readList(']')
+		if !ok {
+			return "", false
+		}
+		switch name {
+		case "T::Array":
+			name = "Array"
+		case "T::Hash":
+			name = "Hash"
+		case "T::Set":
+			name = "Set"
+		}
+		return name + "[" + strings.Join(args, ", ") + "]", true
+	case r.consume('('):
+		args, ok := r.readList(')')
+		if !ok {
+			return "", false
+		}
+		switch name {
+		case "T.nilable":
+			if len(args) != 1 {
+				return "", false
+			}
+			if strings.HasSuffix(args[0], "?") || args[0] == "untyped" {
+				return args[0], true
+			}
+			return args[0] + "?", true
+		case "T.any":
+			return "(" + strings.Join(args, " | ") + ")", true
+		case "T.all":
+			return "(" + strings.Join(args, " & ") + ")", true
+		}
+		return "", false
+	}
+
+	switch name {
+	case "T.untyped":
+		return "untyped", true
+	case "T::Boolean":
+		return "bool", true
+	case "T.self_type":
+		return "instance", true
+	}
+	if alias, ok := r.aliases[name]; ok {
+		return alias, true
+	}
+	return name, true
+}
+
+// readList reads a comma-separated list of types, up to and including the closing character
+func (r *rbsTypeReader) readList(closing byte) ([]string, bool) {
+	var items []string
+	for {
+		item, ok := r.read()
+		if !ok {
+			return nil, false
+		}
+		items = append(items, item)
+
+		r.skipSpaces()
+		if r.consume(closing) {
+			return items, true
+		}
+		if !r.consume(',') {
+			return nil, false
+		}
+	}
+}
+
+func (r *rbsTypeReader) consume(c byte) bool {
+	if r.pos < len(r.s) && r.s[r.pos] == c {
+		r.pos++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRBSType(t *testing.T) {
+	aliases := map[string]string{"PetId": "pet_id"}
+	tests := []struct {
+		ty   string
+		want string
+	}{
+		{ty: "String", want: "String"},
+		{ty: "T.nilable(T::Array[String])", want: "Array[String]?"},
+		{ty: "T::Hash[String, T.untyped]", want: "Hash[String, untyped]"},
+		{ty: "T.nilable(T.untyped)", want: "untyped"},
+		{ty: "T.any(String, Integer)", want: "(String | Integer)"},
+		{ty: "T.all(Named, Aged)", want: "(Named & Aged)"},
+		{ty: "T::Boolean", want: "bool"},
+		{ty: "[String, PetId]", want: "[String, pet_id]"},
+		{ty: "T.nilable(T.nilable(String))", want: "String?"},
+		{ty: "T.unknown(String)", want: "untyped"},
+		{ty: "T::Array[String", want: "untyped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ty, func(t *testing.T) {
+			if got := rbsType(tt.ty, aliases); got != tt.want {
+				t.Errorf("rbsType(%q) = %q, want %q", tt.ty, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRBS(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    PetId:
+      type: string
+    Pet:
+      type: object
+      required: [id]
+      properties:
+        id: {$ref: '#/components/schemas/PetId'}
+        tags: {type: array, items: {type: string}}
+        status: {$ref: '#/components/schemas/Status'}
+    Status:
+      type: string
+      enum: [available, sold]
+`), Options{Format: FormatRBS})
+
+	assertContains(t, files, "pet.rbs",
+		"# Generated from OpenAPI specification for\n",
+		"class Pet\n  include HashDeserializable\n  extend HashDeserializable::ClassMethods\n",
+		"  attr_reader id: pet_id\n",
+		"  attr_reader tags: Array[String]?\n",
+		"  def initialize: (id: pet_id, ?status: Status?, ?tags: Array[String]?) -> void\n",
+	)
+	assertContains(t, files, "pet_id.rbs", "type pet_id = String")
+	assertContains(t, files, "status.rbs", "class Status\n  Available: Status\n")
+	assertContains(t, files, "hash_deserializable.rbs", "def from_hash: (Hash[Symbol, untyped] hash) -> untyped")
+
+	for name := range files {
+		if filepath.Ext(name) != ".rbs" {
+			t.Errorf("Generate() wrote %s, but only RBS files should be generated", name)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaRefPrefixes are the locations of the schemas that types are generated for, in OpenAPI 3 and Swagger 2.0
+// documents respectively
+var schemaRefPrefixes = []string{"#/components/schemas/", "#/definitions/"}
+
+// isSchemaRef reports whether the schema is a `$ref` to one of the schemas that types are generated for, in this or
+// another document. Any other `$ref`, such as to the schema of a parameter in `#/components/parameters`, doesn't refer
+// to a generated type, so is resolved and parsed as if the schema were inline
+func isSchemaRef(sp *base.SchemaProxy) bool {
+	return sp.IsReference() && isSchemaRefPath(sp.GetReference())
+}
+
+// isSchemaRefPath reports whether a `$ref` refers to one of the schemas that types are generated for
+func isSchemaRefPath(ref string) bool {
+	i := strings.Index(ref, "#")
+	if i < 0 {
+		return false
+	}
+
+	fragment := ref[i:]
+	for _, prefix := range schemaRefPrefixes {
+		if strings.HasPrefix(fragment, prefix) && !strings.Contains(fragment[len(prefix):], "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// refSchemaName returns the name of the schema a `$ref` refers to, i.e. `#/components/schemas/pet` will be `pet`
+func refSchemaName(ref string) string {
+	parts := strings.Split(ref, "/")
+	return parts[len(parts)-1]
+}
+
+// refKey identifies a `$ref` by the document it's written in, as the same `$ref` may resolve to a different schema in
+// each of the documents being merged
+type refKey struct {
+	document string
+	ref      string
+}
+
+// parsedRef is the type name and types that the schema a `$ref` resolves to was parsed as
+type parsedRef struct {
+	typeName string
+	types    []Type
+}
+
+// refCache memoizes the schemas that `$ref`s to generated types resolve to, and the types parsed from the schemas
+// that other `$ref`s resolve to, keyed by the document and `$ref`, as otherwise each occurrence of the same `$ref` is
+// resolved and parsed separately. It's safe for concurrent use
+type refCache struct {
+	mu      sync.Mutex
+	schemas map[refKey]*base.Schema
+	parsed  map[refKey]parsedRef
+}
+
+// schema returns the schema that sp, in the document, resolves to, which is cached if it's a `$ref` to a generated
+// type. Schemas which fail to resolve aren't cached, so the error is available from sp
+func (c *refCache) schema(document string, sp *base.SchemaProxy) *base.Schema {
+	if !isSchemaRef(sp) {
+		return sp.Schema()
+	}
+
+	key := refKey{document: document, ref: sp.GetReference()}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if schema, ok := c.schemas[key]; ok {
+		return schema
+	}
+
+	schema := sp.Schema()
+	if schema != nil {
+		if c.schemas == nil {
+			c.schemas = make(map[refKey]*base.Schema)
+		}
+		c.schemas[key] = schema
+	}
+	return schema
+}
+
+// parse returns the type name and types that sp, in the document, is parsed as by parse. A `$ref` which isn't to a
+// generated type, such as to the schema of a parameter, is only parsed the first time it occurs, so generated is false
+// when the types were already returned for an earlier occurrence, and mustn't be generated again
+func (c *refCache) parse(document string, sp *base.SchemaProxy, parse func() (string, []Type)) (typeName string, types []Type, generated bool) {
+	if !sp.IsReference() || isSchemaRef(sp) {
+		typeName, types = parse()
+		return typeName, types, true
+	}
+
+	key := refKey{document: document, ref: sp.GetReference()}
+
+	c.mu.Lock()
+	cached, ok := c.parsed[key]
+	c.mu.Unlock()
+	if ok {
+		return cached.typeName, cached.types, false
+	}
+
+	// the lock isn't held while parsing, as the schema may contain other `$ref`s to parse
+	typeName, types = parse()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// if the `$ref` was parsed concurrently, only the first of the parsed types is generated
+	if cached, ok := c.parsed[key]; ok {
+		return cached.typeName, cached.types, false
+	}
+	if c.parsed == nil {
+		c.parsed = make(map[refKey]parsedRef)
+	}
+	c.parsed[key] = parsedRef{typeName: typeName, types: types}
+	return typeName, types, true
+}
+
+// refSiblings contains the keywords alongside a property's `$ref`, such as its `description`, which are discarded when
+// the `$ref` is resolved
+type refSiblings struct {
+	Description string
+	Nullable    bool
+	Deprecated  bool
+	// Default is the decoded `default`, if HasDefault is set, as it may be `null`
+	Default    any
+	HasDefault bool
+}
+
+// propertyRefSiblings returns the refSiblings of each of the schema's properties which is a `$ref` alongside other
+// keywords. They're read from the schema's `properties` as they're written in the document, as the properties'
+// schemas are instead those that they refer to
+func propertyRefSiblings(v *base.Schema) map[string]refSiblings {
+	siblings := make(map[string]refSiblings)
+	if v.GoLow() == nil || v.GoLow().Properties.ValueNode == nil {
+		return siblings
+	}
+
+	properties := v.GoLow().Properties.ValueNode
+	for i := 0; i+1 < len(properties.Content); i += 2 {
+		name, node := properties.Content[i].Value, properties.Content[i+1]
+		if node.Kind != yaml.MappingNode {
+			continue
+		}
+
+		var s refSiblings
+		isRef, hasSiblings := false, false
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			value := node.Content[j+1]
+			switch node.Content[j].Value {
+			case "$ref":
+				isRef = true
+				continue
+			case "description":
+				s.Description = value.Value
+			case "nullable":
+				s.Nullable = value.Value == "true"
+			case "deprecated":
+				s.Deprecated = value.Value == "true"
+			case "default":
+				if err := value.Decode(&s.Default); err != nil {
+					continue
+				}
+				s.HasDefault = true
+			default:
+				continue
+			}
+			hasSiblings = true
+		}
+
+		if isRef && hasSiblings {
+			siblings[name] = s
+		}
+	}
+
+	return siblings
+}
@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+func TestRefsOutsideSchemas(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  parameters:
+    Pet:
+      name: pet
+      in: query
+      schema:
+        type: integer
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+    Search:
+      type: object
+      properties:
+        pet: {$ref: '#/components/schemas/Pet'}
+        petId: {$ref: '#/components/parameters/Pet/schema'}
+    PetParameter: {$ref: '#/components/parameters/Pet/schema'}
+`
+
+	files := generateSpec(t, spec, Options{})
+	assertContains(t, files, "search.rb",
+		"const :pet, T.nilable(Pet)",
+		"const :pet_id, T.nilable(Integer)",
+	)
+	assertContains(t, files, "pet_parameter.rb", "PetParameter = T.type_alias { Integer}")
+}
+
+func TestIsSchemaRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "#/components/schemas/Pet", want: true},
+		{ref: "#/definitions/Pet", want: true},
+		{ref: "pets.yaml#/components/schemas/Pet", want: true},
+		{ref: "#/components/parameters/Pet", want: false},
+		{ref: "#/components/responses/Pet/content/application~1json/schema", want: false},
+		{ref: "#/components/schemas/Pet/properties/name", want: false},
+		{ref: "pets.yaml", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := isSchemaRef(base.CreateSchemaProxyRef(tt.ref)); got != tt.want {
+				t.Errorf("isSchemaRef(%q) = %v, want %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepeatedRefIsGeneratedOnce(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "object",
+			schema: "{type: object, properties: {filter: {$ref: '#/components/parameters/Filter/schema'}}}",
+			want:   "const :filter, T.nilable(OwnerFilter)",
+		},
+		{
+			name:   "array items",
+			schema: "{type: object, properties: {filter: {type: array, items: {$ref: '#/components/parameters/Filter/schema'}}}}",
+			want:   "const :filter, T.nilable(T::Array[OwnerFilter])",
+		},
+		{
+			name:   "union member",
+			schema: "{oneOf: [{$ref: '#/components/parameters/Filter/schema'}, {$ref: '#/components/schemas/Owner'}]}",
+			want:   "Pet = T.type_alias { T.any(OwnerFilter, Owner)}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  parameters:
+    Filter:
+      name: filter
+      in: query
+      schema:
+        type: object
+        properties:
+          query: {type: string}
+  schemas:
+    Owner:
+      type: object
+      properties:
+        filter:
+          $ref: '#/components/parameters/Filter/schema'
+    Pet: ` + tt.schema + `
+`
+			files := generateSpec(t, spec, Options{})
+
+			want := []string{"hash_deserializable.rb", "owner.rb", "owner_filter.rb", "pet.rb", "types.rb"}
+			if got := sortedKeys(files); !reflect.DeepEqual(got, want) {
+				t.Errorf("generated %v, want %v", got, want)
+			}
+			assertContains(t, files, "pet.rb", tt.want)
+		})
+	}
+}
+
+func TestRefSiblings(t *testing.T) {
+	spec := specWithSchemas(`
+    Pet:
+      type: object
+      required: [owner, status, wrapped]
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+          nullable: true
+          description: The owner of the pet
+        status:
+          $ref: '#/components/schemas/Status'
+          default: sold
+          deprecated: true
+        wrapped:
+          allOf: [{$ref: '#/components/schemas/Status'}]
+          default: available
+        invalid:
+          $ref: '#/components/schemas/Status'
+          default: unknown
+    Owner:
+      type: object
+      properties:
+        id: {type: integer}
+    Status:
+      type: string
+      enum: [available, sold]
+`)
+
+	files, logs := generateSpecLogs(t, spec, Options{})
+	assertContains(t, files, "pet.rb",
+		"# The owner of the pet\n  const :owner, T.nilable(Owner)\n",
+		"const :status, Status, default: Status.deserialize('sold')\n",
+		"const :wrapped, Status, default: Status.deserialize('available')\n",
+		"const :invalid, T.nilable(Status)\n",
+	)
+	if want := "WARN: Pet.invalid has a default (`  'unknown' `) which isn't one of its enum values"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+}
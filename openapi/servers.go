@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"golang.org/x/exp/slices"
+)
+
+// serversFilename is the name of the file, without extension, that the Servers are generated in
+const serversFilename = "servers"
+
+// writeServers writes a `Servers` module to the output directory, with a `BASE_URL` constant of the first server's URL,
+// and a `URLS` constant of every server's URL, if Servers is enabled. The file has the OutFileExt of the types' files, and the
+// name to require it by is returned if it was written
+func writeServers(w *fileWriter, opts Options, templates *template.Template, metadata Metadata, outPath string, servers []server, types []Type) (string, error) {
+	if !opts.Servers {
+		return "", nil
+	}
+	if len(servers) == 0 {
+		w.log.warnf("The document doesn't declare any servers, so %s won't be generated", serversFilename+opts.OutFileExt)
+		return "", nil
+	}
+	if slices.IndexFunc(types, func(t Type) bool { return t.Filename == serversFilename }) >= 0 {
+		return "", fmt.Errorf("the servers can't be generated in %s, as a type is already generated in it", serversFilename+opts.OutFileExt)
+	}
+
+	type serverData struct {
+		URL         string
+		Description string
+	}
+	data := struct {
+		Metadata Metadata
+		BaseURL  string
+		Servers  []serverData
+	}{
+		Metadata: metadata,
+		BaseURL:  rubyString(servers[0].URL),
+	}
+	for _, s := range servers {
+		// only the first line of the description fits alongside the URL
+		description, _, _ := strings.Cut(strings.TrimSpace(s.Description), "\n")
+		data.Servers = append(data.Servers, serverData{URL: rubyString(s.URL), Description: description})
+	}
+
+	err := renderFile(w, filepath.Join(outPath, serversFilename+opts.OutFileExt), templates, "servers."+opts.Format+".tmpl", data)
+	if err != nil {
+		return "", err
+	}
+
+	w.generated("Generated %s with the servers' URLs", serversFilename+opts.OutFileExt)
+
+	return serversFilename + metadata.RequireSuffix, nil
+}
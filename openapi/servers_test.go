@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServers(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+servers:
+  - url: https://api.example.com/v1
+    description: Production
+  - url: https://{region}.example.com/v1
+paths: {}
+components:
+  schemas:
+    Pet: {type: object, properties: {name: {type: string}}}
+`
+
+	files := generateSpec(t, spec, Options{Servers: true})
+	assertContains(t, files, "servers.rb",
+		"module Servers\n",
+		"  BASE_URL = T.let('https://api.example.com/v1', String)\n",
+		"    'https://api.example.com/v1', # Production\n    'https://{region}.example.com/v1',\n  ].freeze, T::Array[String])\n",
+	)
+
+	t.Run("swagger 2.0", func(t *testing.T) {
+		files := generateSpec(t, "swagger: '2.0'\ninfo: {title: Test, version: '1'}\nhost: api.example.com\nbasePath: /v1\nschemes: [https, http]\npaths: {}\n", Options{Servers: true})
+		assertContains(t, files, "servers.rb", "BASE_URL = T.let('https://api.example.com/v1', String)", "'http://api.example.com/v1',")
+	})
+
+	t.Run("no servers", func(t *testing.T) {
+		files, logs := generateSpecLogs(t, specWithSchemas("\n    Pet: {type: object}\n"), Options{Servers: true})
+		if _, ok := files["servers.rb"]; ok {
+			t.Errorf("servers.rb was generated, but the document doesn't declare any servers")
+		}
+		if want := "WARN: The document doesn't declare any servers"; !strings.Contains(logs, want) {
+			t.Errorf("didn't log %q:\n%s", want, logs)
+		}
+	})
+}
@@ -0,0 +1,438 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	SorbetUntyped = "T.untyped"
+)
+
+type Metadata struct {
+	Command string
+	Version string
+	// TypedSigil is the strictness level of the `# typed:` sigil for generated files
+	TypedSigil string
+	// FrozenStringLiteral indicates whether generated files should have the `# frozen_string_literal: true` magic comment
+	FrozenStringLiteral bool
+	// GeneratedAt is the time that the files were generated, if it should be included in the header
+	GeneratedAt string
+
+	Modules []string
+	// RequireSuffix is appended to the names of generated types' files in a `require_relative`, being the part of the
+	// OutFileExt before `.rb`, i.e. `.gen` for `.gen.rb`
+	RequireSuffix string
+	// ModuleStyle is how the Modules are opened, either ModuleStyleNested or ModuleStyleCompact
+	ModuleStyle string
+
+	Spec struct {
+		Title   string
+		Version string
+		// Description contains the `description` of the API, if set
+		Description string
+		// Contact contains the name, email and URL of the API's `contact`, if set
+		Contact string
+		// License contains the name and URL of the API's `license`, if set
+		License string
+	}
+}
+
+// SpecDetails returns the Spec's Description, Contact and License, if set, to document the API in the header
+func (m Metadata) SpecDetails() string {
+	var paragraphs []string
+	if m.Spec.Description != "" {
+		paragraphs = append(paragraphs, m.Spec.Description)
+	}
+
+	var lines []string
+	if m.Spec.Contact != "" {
+		lines = append(lines, "Contact: "+m.Spec.Contact)
+	}
+	if m.Spec.License != "" {
+		lines = append(lines, "License: "+m.Spec.License)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// OpenModules returns the lines opening each of the Modules, indented by their nesting, or the single line opening
+// all of them with ModuleStyleCompact
+func (m Metadata) OpenModules() string {
+	if m.ModuleStyle == ModuleStyleCompact && len(m.Modules) > 0 {
+		return "module " + strings.Join(m.Modules, "::") + "\n"
+	}
+
+	var sb strings.Builder
+	for i, module := range m.Modules {
+		sb.WriteString(indent(i, "module "+module) + "\n")
+	}
+	return sb.String()
+}
+
+// CloseModules returns the lines closing each of the Modules, indented by their nesting, or the single line closing
+// all of them with ModuleStyleCompact
+func (m Metadata) CloseModules() string {
+	var sb strings.Builder
+	for i := m.ModuleDepth() - 1; i >= 0; i-- {
+		sb.WriteString("\n" + indent(i, "end"))
+	}
+	return sb.String()
+}
+
+// ModuleDepth returns the depth that types are nested within the Modules, so should be indented by
+func (m Metadata) ModuleDepth() int {
+	if m.ModuleStyle == ModuleStyleCompact && len(m.Modules) > 0 {
+		return 1
+	}
+	return len(m.Modules)
+}
+
+type Type struct {
+	SchemaName           string
+	TypeName             string
+	Filename             string
+	Type                 string
+	Comment              string
+	BaseClass            string
+	Properties           []Property
+	Enum                 []Enum
+	Alias                string
+	AdditionalProperties string
+	// MapKeyType is the Sorbet type for the keys of an object's AdditionalProperties
+	MapKeyType string
+	// Union contains the member types, if this Type is a union of other types
+	Union []string
+	// RelativeRequires contains the files of the other generated types that this Type references
+	RelativeRequires []string
+	// Discriminator is set if this Type is a `oneOf` with a `discriminator`, which is generated as a module that each
+	// of its members includes
+	Discriminator *Discriminator
+	// Interfaces contains the discriminated Types, and interface bases, that this struct is a member of, and so should
+	// include
+	Interfaces []string
+
+	IsArray bool
+	// IsMap indicates whether the object should be generated as a `T::Hash` type alias, rather than a struct
+	IsMap bool
+	// Nilable indicates that the type alias may also be `nil`
+	Nilable bool
+	// Numeric contains the constraints of an `integer` or `number` schema, if it has any
+	Numeric *NumericConstraints
+	// IsStringEnum indicates whether the Enum values are strings, and so need quoting when rendered
+	IsStringEnum bool
+	// Deprecated indicates that the schema is marked as `deprecated`
+	Deprecated bool
+	// Mutable indicates that the struct's properties should be generated with `prop`, rather than `const`
+	Mutable bool
+	// Serializable indicates that the struct should include `T::Props::Serializable`, for `serialize` and `from_hash`
+	Serializable bool
+	// Validations indicates that the struct should have a `validate!` method enforcing its properties' constraints
+	Validations bool
+	// IsInterface indicates that the object should be generated as an interface, with an abstract method for each
+	// property, as it's only used as an `allOf` base of other structs
+	IsInterface bool
+	// IsWrapper indicates that the type alias should instead be generated as a class wrapping a value of the type
+	IsWrapper bool
+	// IsRefAlias indicates that the schema is only a `$ref` to another schema, so is generated as a constant referring
+	// to the referenced type, which is its Alias
+	IsRefAlias bool
+	// Closed indicates that the struct's `additionalProperties` is false, so undeclared keys are rejected when it's
+	// deserialized from a hash
+	Closed bool
+	// Sealed indicates that the Discriminator's module can be `sealed!`, as its members are generated in the same file
+	Sealed bool
+}
+
+func (t Type) IsObject() bool {
+	return t.BaseClass != "" && !t.IsEnum()
+}
+
+func (t Type) IsEnum() bool {
+	return "T::Enum" == t.BaseClass
+}
+
+// IsAlias reports whether the Type is generated as a type alias, or a wrapper class of one, rather than a struct,
+// enum, or module
+func (t Type) IsAlias() bool {
+	return t.IsMap || (!t.IsObject() && !t.IsEnum() && !t.IsInterface && t.Discriminator == nil)
+}
+
+// SorbetAlias returns the Sorbet type that a Type which isn't a class is an alias of
+func (t Type) SorbetAlias() string {
+	if t.IsMap {
+		return fmt.Sprintf("T::Hash[%s, %s]", t.MapKeyType, t.AdditionalProperties)
+	}
+
+	ty := t.Alias
+	if ty == "" {
+		ty = "String"
+	}
+	if t.IsArray {
+		ty = fmt.Sprintf("T::Array[%s]", ty)
+	}
+	if t.Nilable {
+		ty = fmt.Sprintf("T.nilable(%s)", ty)
+	}
+	return ty
+}
+
+// HasSigs reports whether the Type's class or module declares any method signatures, so needs to `extend T::Sig`
+func (t Type) HasSigs() bool {
+	switch {
+	case t.IsWrapper, t.Discriminator != nil:
+		return true
+	case t.IsInterface:
+		return len(t.Properties) > 0
+	case t.IsObject() && !t.IsMap:
+		return t.Closed || t.HasValidations()
+	}
+	return false
+}
+
+// HasValidations reports whether a `validate!` method should be generated, as Validations are enabled, and at least
+// one property has constraints to enforce
+func (t Type) HasValidations() bool {
+	if !t.Validations {
+		return false
+	}
+	for _, p := range t.Properties {
+		if len(p.RubyValidations()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type Property struct {
+	Ref        string
+	Name       string
+	Type       string
+	SchemaName string
+	Required   bool
+	IsArray    bool
+	// Nullable indicates that the property may be `null`, even if it is Required
+	Nullable bool
+	// Format contains the `format` of the property's schema, if it should be annotated
+	Format string
+	// Comment contains the `description` of the property's schema
+	Comment string
+	// Deprecated indicates that the property's schema is marked as `deprecated`
+	Deprecated bool
+	// ReadOnly indicates that the property's schema is marked as `readOnly`, so is only present in responses
+	ReadOnly bool
+	// WriteOnly indicates that the property's schema is marked as `writeOnly`, so is only present in requests
+	WriteOnly bool
+	// Default contains the Ruby literal for the property's `default`, if set
+	Default string
+	// CollectionDefault indicates that the optional array or map property defaults to an empty collection, rather than
+	// `nil`, so isn't nilable
+	CollectionDefault bool
+	// Mutable indicates that the property should be generated with `prop`, rather than `const`
+	Mutable bool
+	// Pattern contains the `pattern` of a string property's schema
+	Pattern string
+	// MinLength contains the `minLength` of a string property's schema
+	MinLength *int64
+	// MaxLength contains the `maxLength` of a string property's schema
+	MaxLength *int64
+	// Numeric contains the constraints of an `integer` or `number` property's schema, if it has any
+	Numeric *NumericConstraints
+}
+
+// NumericConstraints contains the constraints of an `integer` or `number` schema. Each is a number literal, i.e. `0.5`,
+// or empty if it isn't set
+type NumericConstraints struct {
+	// Minimum contains the `minimum`, or `exclusiveMinimum`, if set
+	Minimum json.Number
+	// ExclusiveMinimum indicates that the value must be greater than the Minimum, rather than equal to or greater
+	ExclusiveMinimum bool
+	// Maximum contains the `maximum`, or `exclusiveMaximum`, if set
+	Maximum json.Number
+	// ExclusiveMaximum indicates that the value must be less than the Maximum, rather than equal to or less
+	ExclusiveMaximum bool
+	// MultipleOf contains the `multipleOf`, if set
+	MultipleOf json.Number
+}
+
+// String returns the constraints as they're written in the schema, i.e. `minimum: 0, maximum: 100`
+func (c *NumericConstraints) String() string {
+	var constraints []string
+	if c.Minimum != "" && c.ExclusiveMinimum {
+		constraints = append(constraints, fmt.Sprintf("exclusiveMinimum: %s", c.Minimum))
+	} else if c.Minimum != "" {
+		constraints = append(constraints, fmt.Sprintf("minimum: %s", c.Minimum))
+	}
+	if c.Maximum != "" && c.ExclusiveMaximum {
+		constraints = append(constraints, fmt.Sprintf("exclusiveMaximum: %s", c.Maximum))
+	} else if c.Maximum != "" {
+		constraints = append(constraints, fmt.Sprintf("maximum: %s", c.Maximum))
+	}
+	if c.MultipleOf != "" {
+		constraints = append(constraints, fmt.Sprintf("multipleOf: %s", c.MultipleOf))
+	}
+	return strings.Join(constraints, ", ")
+}
+
+// Discriminator describes how a discriminated Type determines which of its members to deserialize a hash as
+type Discriminator struct {
+	// PropertyName is the name of the property in the schema whose value determines the member
+	PropertyName string
+	// Property is the Ruby name of the PropertyName
+	Property string
+	// Mapping contains the members of the discriminated Type, sorted by their Value
+	Mapping []DiscriminatorMapping
+}
+
+type DiscriminatorMapping struct {
+	// Value contains the value of the discriminator property for this member
+	Value string
+	// Literal contains the Ruby literal for the Value
+	Literal string
+	// TypeName is the name of the member's Type
+	TypeName string
+}
+
+type Enum struct {
+	// Name contains the Ruby name for the enum value
+	Name string
+	// Value contains the value name as defined in the schema
+	Value string
+	// Literal contains the Ruby literal for the Value, quoted and escaped if it is a string
+	Literal string
+	// Comment contains the description of the enum value, from the `x-enum-descriptions` extension
+	Comment string
+}
+
+// SorbetType returns the Sorbet type of the property, taking into account whether it's an array, or may be `nil`
+func (p *Property) SorbetType() string {
+	ty := p.Type
+	if p.IsArray {
+		ty = fmt.Sprintf("T::Array[%s]", ty)
+	}
+
+	if (p.Required || p.CollectionDefault) && !p.Nullable {
+		return ty
+	}
+	return fmt.Sprintf("T.nilable(%s)", ty)
+}
+
+// IsOptional reports whether the property can be omitted when constructing its struct, as it's nilable or has a
+// default
+func (p *Property) IsOptional() bool {
+	return !p.Required || p.Nullable || p.Default != ""
+}
+
+func (p *Property) RubyDefinition() string {
+	keyword := "const"
+	if p.Mutable {
+		keyword = "prop"
+	}
+
+	s := fmt.Sprintf("%s :%s, %s", keyword, p.Name, p.SorbetType())
+
+	if p.Default != "" {
+		s += fmt.Sprintf(", default: %s", p.Default)
+	}
+
+	if p.SchemaName != p.Name {
+		s += fmt.Sprintf(", name: %s", rubyString(p.SchemaName))
+	}
+
+	var annotations []string
+	if p.Format != "" {
+		annotations = append(annotations, "format: "+p.Format)
+	}
+	if p.Pattern != "" {
+		annotations = append(annotations, "pattern: "+rubyRegexp(p.Pattern))
+	}
+	if p.MinLength != nil {
+		annotations = append(annotations, fmt.Sprintf("minLength: %d", *p.MinLength))
+	}
+	if p.MaxLength != nil {
+		annotations = append(annotations, fmt.Sprintf("maxLength: %d", *p.MaxLength))
+	}
+	if len(annotations) > 0 {
+		s += " # " + strings.Join(annotations, ", ")
+	}
+
+	return s
+}
+
+// RubyComment returns the Comment as Ruby comment lines
+func (p *Property) RubyComment() string {
+	return rubyComment(p.Comment)
+}
+
+// rubyComment returns the text as Ruby comment lines
+func rubyComment(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			lines = append(lines, "#")
+		} else {
+			lines = append(lines, "# "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// uuidRegexp is the Ruby literal for a regular expression matching a UUID, in any version, and either case
+const uuidRegexp = `/\A\h{8}-\h{4}-\h{4}-\h{4}-\h{12}\z/`
+
+// RubyValidations returns the Ruby statements that enforce the property's constraints, raising an ArgumentError if
+// they're not met. Constraints can only be enforced on String, Integer and Float properties
+func (p *Property) RubyValidations() []string {
+	if (p.Type != "String" && p.Type != "Integer" && p.Type != "Float") || p.IsArray {
+		return nil
+	}
+
+	guard := "unless "
+	value := p.Name
+	if !p.Required || p.Nullable {
+		// Sorbet doesn't narrow the type of a method call, so the value needs asserting as non-nil
+		guard += p.Name + ".nil? || "
+		value = fmt.Sprintf("T.must(%s)", p.Name)
+	}
+
+	var validations []string
+	if p.Pattern != "" {
+		re := rubyRegexp(p.Pattern)
+		validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s.match?(%s)", rubyString(p.Name+" must match "+re), guard, value, re))
+	}
+	if p.MinLength != nil {
+		validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s.length >= %d", rubyString(fmt.Sprintf("%s must be at least %d characters", p.Name, *p.MinLength)), guard, value, *p.MinLength))
+	}
+	if p.MaxLength != nil {
+		validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s.length <= %d", rubyString(fmt.Sprintf("%s must be at most %d characters", p.Name, *p.MaxLength)), guard, value, *p.MaxLength))
+	}
+	if p.Format == "uuid" && p.Type == "String" {
+		validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s.match?(%s)", rubyString(p.Name+" must be a UUID"), guard, value, uuidRegexp))
+	}
+	if c := p.Numeric; c != nil {
+		if c.Minimum != "" && c.ExclusiveMinimum {
+			validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s > %s", rubyString(fmt.Sprintf("%s must be greater than %s", p.Name, c.Minimum)), guard, value, c.Minimum))
+		} else if c.Minimum != "" {
+			validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s >= %s", rubyString(fmt.Sprintf("%s must be at least %s", p.Name, c.Minimum)), guard, value, c.Minimum))
+		}
+		if c.Maximum != "" && c.ExclusiveMaximum {
+			validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s < %s", rubyString(fmt.Sprintf("%s must be less than %s", p.Name, c.Maximum)), guard, value, c.Maximum))
+		} else if c.Maximum != "" {
+			validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s <= %s", rubyString(fmt.Sprintf("%s must be at most %s", p.Name, c.Maximum)), guard, value, c.Maximum))
+		}
+		if f, err := c.MultipleOf.Float64(); err == nil && f != 0 {
+			check := fmt.Sprintf("(%s %% %s).zero?", value, c.MultipleOf)
+			if _, err := c.MultipleOf.Int64(); err != nil {
+				// a float remainder is rarely exactly zero, so the decimal values are compared as rationals instead
+				check = fmt.Sprintf("(%s.to_s.to_r %% %sr).zero?", value, c.MultipleOf)
+			}
+			validations = append(validations, fmt.Sprintf("raise ArgumentError, %s %s%s", rubyString(fmt.Sprintf("%s must be a multiple of %s", p.Name, c.MultipleOf)), guard, check))
+		}
+	}
+	return validations
+}
@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// unionNameInvalid matches the characters of a union member's Sorbet type which can't be used in the name of a type
+var unionNameInvalid = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// hoistUnions replaces each `T.any` union which is the type of more than one property with a named type alias, so it's
+// only declared once. Unions are the same if they have the same members, in any order, and an existing union type
+// alias with the same members is reused, rather than generating another
+func (p *parser) hoistUnions(types []Type) []Type {
+	counts := make(map[string]int)
+	for _, t := range types {
+		for _, prop := range t.Properties {
+			if members, ok := unionMembersOf(prop.Type); ok {
+				counts[unionKey(members)]++
+			}
+		}
+	}
+
+	aliases := make(map[string]string)
+	for _, t := range types {
+		if !isPlainUnion(t) {
+			continue
+		}
+		if members, ok := unionMembersOf(t.Alias); ok {
+			key := unionKey(members)
+			if _, ok := aliases[key]; !ok && counts[key] > 1 {
+				aliases[key] = t.TypeName
+			}
+		}
+	}
+
+	for i := range types {
+		for j := range types[i].Properties {
+			prop := &types[i].Properties[j]
+			members, ok := unionMembersOf(prop.Type)
+			if !ok {
+				continue
+			}
+
+			key := unionKey(members)
+			if counts[key] < 2 {
+				continue
+			}
+
+			if _, ok := aliases[key]; !ok {
+				t := p.unionAlias(members, types)
+				p.log.infof("Hoisting %s, which is used by %d properties, into %s", key, counts[key], t.TypeName)
+				aliases[key] = t.TypeName
+				types = append(types, t)
+			}
+			prop.Type = aliases[key]
+		}
+	}
+
+	return types
+}
+
+// unionAlias returns a type alias for a union of members, named after them, i.e. `StringOrInteger`
+func (p *parser) unionAlias(members []string, types []Type) Type {
+	var parts []string
+	for _, member := range members {
+		member = strings.TrimPrefix(strings.TrimPrefix(member, "T::"), "T.")
+		parts = append(parts, strings.Trim(unionNameInvalid.ReplaceAllString(member, "_"), "_"))
+	}
+	name := strings.Join(parts, "_or_")
+
+	// don't clash with a schema which happens to have the same name
+	for slices.IndexFunc(types, func(t Type) bool { return t.TypeName == p.typeName(name) }) >= 0 {
+		name += "_union"
+	}
+
+	t := Type{}
+	t.SchemaName = name
+	t.TypeName = p.typeName(name)
+	t.Filename = p.fileName(name)
+	t.Comment = "A union used by multiple properties"
+	t.Union = members
+	t.Alias = sorbetUnion(members)
+	return t
+}
+
+// isPlainUnion reports whether the Type is generated as a type alias of only a union, so can be referred to in place
+// of it
+func isPlainUnion(t Type) bool {
+	return t.IsAlias() && !t.IsWrapper && !t.IsRefAlias && !t.IsArray && !t.IsMap && !t.Nilable
+}
+
+// unionMembersOf returns the members of a Sorbet `T.any` type, if ty is one
+func unionMembersOf(ty string) ([]string, bool) {
+	if !strings.HasPrefix(ty, "T.any(") || !strings.HasSuffix(ty, ")") {
+		return nil, false
+	}
+
+	var members []string
+	depth, start := 0, len("T.any(")
+	for i := start; i < len(ty)-1; i++ {
+		switch ty[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				// the closing bracket of the `T.any` isn't the last character, i.e. `T.any(A).foo(B)`
+				return nil, false
+			}
+		case ',':
+			if depth == 0 {
+				members = append(members, strings.TrimSpace(ty[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	members = append(members, strings.TrimSpace(ty[start:len(ty)-1]))
+
+	return members, depth == 0
+}
+
+// unionKey returns the union of members in a consistent order, so unions which only differ in the order of their
+// members are treated as the same
+func unionKey(members []string) string {
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	slices.Sort(sorted)
+	return sorbetUnion(sorted)
+}
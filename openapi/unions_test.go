@@ -0,0 +1,75 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHoistUnions(t *testing.T) {
+	spec := specWithSchemas(`
+    Cat: {type: object, properties: {name: {type: string}}}
+    Dog: {type: object, properties: {name: {type: string}}}
+    Fish: {type: object, properties: {name: {type: string}}}
+    Home:
+      type: object
+      properties:
+        pet: {oneOf: [{$ref: '#/components/schemas/Cat'}, {$ref: '#/components/schemas/Dog'}]}
+        favourite: {oneOf: [{$ref: '#/components/schemas/Dog'}, {$ref: '#/components/schemas/Cat'}]}
+        aquarium: {oneOf: [{$ref: '#/components/schemas/Fish'}, {$ref: '#/components/schemas/Cat'}]}
+    Owner:
+      type: object
+      properties:
+        pet: {anyOf: [{$ref: '#/components/schemas/Cat'}, {$ref: '#/components/schemas/Dog'}]}
+`)
+
+	t.Run("hoisted", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{HoistUnions: true})
+		assertContains(t, files, "dog_or_cat.rb", "DogOrCat = T.type_alias { T.any(Dog, Cat)}")
+		assertContains(t, files, "home.rb",
+			"const :pet, T.nilable(DogOrCat)",
+			"const :favourite, T.nilable(DogOrCat)",
+			"const :aquarium, T.nilable(T.any(Fish, Cat))",
+		)
+		assertContains(t, files, "owner.rb", "const :pet, T.nilable(DogOrCat)")
+	})
+
+	t.Run("existing alias", func(t *testing.T) {
+		spec := strings.Replace(spec, "    Owner:", "    Pet: {oneOf: [{$ref: '#/components/schemas/Dog'}, {$ref: '#/components/schemas/Cat'}]}\n    Owner:", 1)
+		files := generateSpec(t, spec, Options{HoistUnions: true})
+		assertContains(t, files, "home.rb", "const :pet, T.nilable(Pet)", "const :favourite, T.nilable(Pet)")
+		if _, ok := files["dog_or_cat.rb"]; ok {
+			t.Errorf("dog_or_cat.rb was generated, but the Pet alias has the same members")
+		}
+	})
+
+	t.Run("not hoisted", func(t *testing.T) {
+		files := generateSpec(t, spec, Options{})
+		assertContains(t, files, "home.rb", "const :pet, T.nilable(T.any(Cat, Dog))", "const :favourite, T.nilable(T.any(Dog, Cat))")
+		if _, ok := files["dog_or_cat.rb"]; ok {
+			t.Errorf("dog_or_cat.rb was generated without HoistUnions")
+		}
+	})
+}
+
+func TestUnionMembersOf(t *testing.T) {
+	tests := []struct {
+		ty   string
+		want []string
+	}{
+		{ty: "T.any(String, Integer)", want: []string{"String", "Integer"}},
+		{ty: "T.any(T::Hash[String, T.any(A, B)], T::Array[C])", want: []string{"T::Hash[String, T.any(A, B)]", "T::Array[C]"}},
+		{ty: "T.nilable(T.any(A, B))"},
+		{ty: "T.any(A).foo(B)"},
+		{ty: "String"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ty, func(t *testing.T) {
+			got, ok := unionMembersOf(tt.ty)
+			if ok != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unionMembersOf(%q) = %q, %v, want %q", tt.ty, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// externalRefs returns the names of the schemas that are `$ref`d from anywhere in the document other than from within
+// the schema itself, such as by the responses of operations, by `#/components/requestBodies`, or by another schema's
+// `allOf`, as these are used even if no generated type refers to them
+func externalRefs(root *yaml.Node) map[string]bool {
+	refs := make(map[string]bool)
+	if root == nil {
+		return refs
+	}
+
+	var walk func(node *yaml.Node, path []string, owner string)
+	walk = func(node *yaml.Node, path []string, owner string) {
+		switch node.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, child := range node.Content {
+				walk(child, path, owner)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i].Value, node.Content[i+1]
+				if key == "$ref" && value.Kind == yaml.ScalarNode && isSchemaRefPath(value.Value) {
+					// a schema referring to itself doesn't make it used
+					if name := refSchemaName(value.Value); name != owner {
+						refs[name] = true
+					}
+					continue
+				}
+
+				childOwner := owner
+				if slices.Equal(path, []string{"components", "schemas"}) || slices.Equal(path, []string{"definitions"}) {
+					childOwner = key
+				}
+				walk(value, append(slices.Clone(path), key), childOwner)
+			}
+		}
+	}
+	walk(root, nil, "")
+
+	return refs
+}
+
+// reportUnused logs the generated types which aren't referenced by any other generated type, nor used through
+// referenced, which contains the names of schemas `$ref`d from elsewhere in the document, such as by operations. Their
+// schemas may no longer be needed, so are candidates for removal from the document
+func reportUnused(log *logger, types []Type, referenced map[string]bool) {
+	required := make(map[string]bool)
+	for _, t := range types {
+		for _, r := range t.RelativeRequires {
+			required[strings.TrimPrefix(r, "./")] = true
+		}
+	}
+
+	// the members of a discriminated union, and the modules and base classes that types include or inherit, may not be
+	// required by the types that use them
+	used := make(map[string]bool)
+	for _, t := range types {
+		if t.Discriminator != nil {
+			for _, m := range t.Discriminator.Mapping {
+				used[m.TypeName] = true
+			}
+		}
+		for _, i := range t.Interfaces {
+			used[i] = true
+		}
+		used[t.BaseClass] = true
+	}
+
+	var unused []string
+	for _, t := range types {
+		if !required[t.Filename] && !used[t.TypeName] && !referenced[t.SchemaName] {
+			unused = append(unused, t.TypeName)
+		}
+	}
+	slices.Sort(unused)
+
+	if len(unused) == 0 {
+		log.infof("All generated types are referenced by another type or an operation")
+		return
+	}
+	log.warnf("%d generated types aren't referenced by any other type or operation, so may be unused: %s", len(unused), strings.Join(unused, ", "))
+}
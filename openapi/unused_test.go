@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportUnused(t *testing.T) {
+	spec := `
+openapi: 3.0.0
+info: {title: Test, version: "1"}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        owner: {$ref: '#/components/schemas/Owner'}
+        animal: {$ref: '#/components/schemas/Animal'}
+    Owner:
+      type: object
+      properties:
+        id: {type: integer}
+    Animal:
+      oneOf: [{$ref: '#/components/schemas/Dog'}]
+      discriminator:
+        propertyName: kind
+        mapping: {dog: '#/components/schemas/Dog'}
+    Dog:
+      type: object
+      properties:
+        kind: {type: string}
+    Orphan:
+      type: object
+      properties:
+        parent: {$ref: '#/components/schemas/Orphan'}
+    Unused:
+      type: string
+`
+
+	_, logs := generateSpecLogs(t, spec, Options{ReportUnused: true})
+	if want := "WARN: 2 generated types aren't referenced by any other type or operation, so may be unused: Orphan, Unused\n"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+
+	_, logs = generateSpecLogs(t, spec, Options{ReportUnused: true, Exclude: []string{"Orphan", "Unused"}})
+	if want := "All generated types are referenced by another type or an operation"; !strings.Contains(logs, want) {
+		t.Errorf("didn't log %q:\n%s", want, logs)
+	}
+
+	_, logs = generateSpecLogs(t, spec, Options{})
+	if strings.Contains(logs, "unused") {
+		t.Errorf("reported unused types, but ReportUnused isn't enabled:\n%s", logs)
+	}
+}
@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// zeitwerkConstant returns the constant name that Zeitwerk's default inflector expects to be defined by the given file
+// or directory name, i.e. `thing_option_2` is expected to define `ThingOption2`
+func zeitwerkConstant(basename string) string {
+	parts := strings.Split(basename, "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// validateZeitwerk checks that each module and type, as well as `hash_deserializable.rb`, would be resolved by Zeitwerk
+// to the directory or file it is generated in, as otherwise it will fail to autoload. `types.rb` only requires the
+// other files, rather than defining a `Types` constant, so must instead be ignored by the Zeitwerk loader
+func validateZeitwerk(modules []string, dirs []string, types []Type) error {
+	var errs []error
+
+	for i, m := range modules {
+		if expected := zeitwerkConstant(dirs[i]); expected != m {
+			errs = append(errs, fmt.Errorf("module %s is generated in directory %s, which Zeitwerk expects to define %s", m, dirs[i], expected))
+		}
+	}
+
+	files := []struct{ constant, filename string }{
+		{"HashDeserializable", "hash_deserializable"},
+	}
+	// validateNames has already rejected any type generated in one of the reservedFileNames
+	for _, t := range types {
+		files = append(files, struct{ constant, filename string }{t.TypeName, t.Filename})
+	}
+
+	for _, f := range files {
+		if expected := zeitwerkConstant(f.filename); expected != f.constant {
+			errs = append(errs, fmt.Errorf("type %s is generated in file %s.rb, which Zeitwerk expects to define %s", f.constant, f.filename, expected))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("generated files are not compatible with Zeitwerk: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZeitwerk(t *testing.T) {
+	files := generateSpec(t, specWithSchemas(`
+    Pet: {type: object, properties: {name: {type: string}}}
+`), Options{Module: "Api::PetStore", Zeitwerk: true})
+
+	assertContains(t, files, "api/pet_store/pet.rb", "module Api\n  module PetStore\n", "class Pet")
+	assertContains(t, files, "api/pet_store/hash_deserializable.rb", "module Api\n  module PetStore\n", "module HashDeserializable")
+}
+
+func TestZeitwerkErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemas string
+		module  string
+		want    []string
+	}{
+		{
+			name:    "module",
+			schemas: "\n    Pet: {type: object}\n",
+			module:  "API::PetStore",
+			want:    []string{"module API is generated in directory api, which Zeitwerk expects to define Api"},
+		},
+		{
+			name:    "type",
+			schemas: "\n    HTTPStatus: {type: integer}\n",
+			module:  "Api",
+			want:    []string{"type HTTPStatus is generated in file http_status.rb, which Zeitwerk expects to define HttpStatus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+
+			_, err := Generate(Options{Input: []byte(specWithSchemas(tt.schemas)), Out: out, Module: tt.module, Zeitwerk: true})
+			if err == nil {
+				t.Fatal("Generate() didn't return an error")
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("Generate() returned the error %q, want it to contain %q", err, w)
+				}
+			}
+			if files := readFiles(t, out); len(files) > 0 {
+				t.Errorf("Generate() wrote files despite the error: %v", sortedKeys(files))
+			}
+		})
+	}
+}